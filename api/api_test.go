@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JSONError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, requiredErr := parseHeaderValue(r, "X-Req", parseString)
+	_, parseErr := parseHeaderValue(requestWithHeader("X-Num", "a"), "X-Num", parseInt)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantDetail string
+	}{
+		{"required", requiredErr, http.StatusUnprocessableEntity, "header X-Req is required"},
+		{"invalid", parseErr, http.StatusUnprocessableEntity, `strconv.Atoi: parsing "a": invalid syntax`},
+		{"unsupported media type", &HTTPError{http.StatusUnsupportedMediaType, errors.New("unsupported media type text/plain")}, http.StatusUnsupportedMediaType, "unsupported media type text/plain"},
+		{"other", errors.New("state mismatch"), http.StatusBadRequest, "state mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			JSONError(w, tt.err)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var p Problem
+			if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p)) {
+				assert.Equal(t, tt.wantStatus, p.Status)
+				assert.Equal(t, tt.wantDetail, p.Detail)
+			}
+		})
+	}
+}
+
+func requestWithHeader(key, value string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(key, value)
+	return r
+}
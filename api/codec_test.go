@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ticket struct {
+	Name string `xml:"name"`
+}
+
+func Test_xmlCodec(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+		new  func() interface{}
+	}{
+		{"object", ticket{Name: "a"}, `<ticket><name>a</name></ticket>`, func() interface{} { return &ticket{} }},
+		{"list", []*ticket{{Name: "a"}, {Name: "b"}}, `<items><ticket><name>a</name></ticket><ticket><name>b</name></ticket></items>`, func() interface{} { return &[]*ticket{} }},
+		{"strings", []string{"a", "b"}, `<items><string>a</string><string>b</string></items>`, func() interface{} { return &[]string{} }},
+		{"empty list", []string{}, `<items></items>`, func() interface{} { return &[]string{} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if !assert.NoError(t, xmlCodec{}.Encode(buf, tt.v)) {
+				return
+			}
+			assert.Equal(t, tt.want, buf.String())
+
+			got := tt.new()
+			if assert.NoError(t, xmlCodec{}.Decode(buf, got)) {
+				assert.Equal(t, tt.v, reflect.ValueOf(got).Elem().Interface())
+			}
+		})
+	}
+}
+
+func Test_parseAccept(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []mediaRange
+	}{
+		{"single", "application/json", []mediaRange{{"application/json", 1}}},
+		{"quality", "application/xml;q=0.5, application/json", []mediaRange{{"application/xml", 0.5}, {"application/json", 1}}},
+		{"specific first", "*/*;q=0.1, text/*;q=0.5, text/plain", []mediaRange{{"text/plain", 1}, {"text/*", 0.5}, {"*/*", 0.1}}},
+		{"invalid quality", "application/json;q=high, text/plain", []mediaRange{{"text/plain", 1}}},
+		{"invalid range", "/, text/plain", []mediaRange{{"text/plain", 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseAccept(tt.header))
+		})
+	}
+}
+
+func Test_negotiate(t *testing.T) {
+	produces := []string{"application/json", "application/xml", "text/plain"}
+
+	tests := []struct {
+		name    string
+		accept  []string
+		want    string
+		wantErr bool
+	}{
+		{"no accept", nil, "application/json", false},
+		{"exact", []string{"application/xml"}, "application/xml", false},
+		{"highest quality", []string{"application/json;q=0.5, text/plain;q=0.8"}, "text/plain", false},
+		{"equal quality keeps order", []string{"text/plain, application/xml"}, "application/xml", false},
+		{"wildcard", []string{"text/*"}, "text/plain", false},
+		{"any", []string{"*/*"}, "application/json", false},
+		{"excluded", []string{"*/*, application/json;q=0"}, "application/xml", false},
+		{"several headers", []string{"image/png", "text/plain"}, "text/plain", false},
+		{"not acceptable", []string{"image/png"}, "", true},
+		{"refused", []string{"*/*;q=0"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header["Accept"] = tt.accept
+
+			e, err := negotiate(r, produces)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, e.mediaType)
+			}
+		})
+	}
+}
+
+func Test_decodeBody(t *testing.T) {
+	consumes := []string{"application/json", "application/xml"}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        ticket
+		wantStatus  int
+	}{
+		{"json", "application/json", `{"Name": "a"}`, ticket{Name: "a"}, 0},
+		{"parameters", "application/json; charset=utf-8", `{"Name": "a"}`, ticket{Name: "a"}, 0},
+		{"xml", "application/xml", `<ticket><name>a</name></ticket>`, ticket{Name: "a"}, 0},
+		{"default", "", `{"Name": "a"}`, ticket{Name: "a"}, 0},
+		{"unsupported", "text/plain", `a`, ticket{}, http.StatusUnsupportedMediaType},
+		{"invalid", "application/", `a`, ticket{}, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			var got ticket
+			err := decodeBody(r, consumes, &got)
+			if tt.wantStatus != 0 {
+				var httpError *HTTPError
+				if assert.ErrorAs(t, err, &httpError) {
+					assert.Equal(t, tt.wantStatus, httpError.Status)
+				}
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
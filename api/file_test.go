@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testBody is a file body that records if it was closed.
+type testBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *testBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+// seekableBody is a testBody that supports range requests.
+type seekableBody struct {
+	*testBody
+	seeker io.Seeker
+}
+
+func (b seekableBody) Seek(offset int64, whence int) (int64, error) {
+	return b.seeker.Seek(offset, whence)
+}
+
+func newTestBody(content string, seekable bool) (io.ReadCloser, *testBody) {
+	reader := strings.NewReader(content)
+	body := &testBody{Reader: reader}
+	if seekable {
+		return seekableBody{body, reader}, body
+	}
+	return body, body
+}
+
+func Test_serveFile(t *testing.T) {
+	modTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		seekable   bool
+		status     int
+		header     http.Header
+		wantStatus int
+		wantBody   string
+		wantHeader http.Header
+	}{
+		{"file", true, http.StatusOK, nil, http.StatusOK, "0123456789", http.Header{"Content-Length": {"10"}, "Content-Disposition": {`attachment; filename=data.txt`}}},
+		{"range", true, http.StatusOK, http.Header{"Range": {"bytes=2-4"}}, http.StatusPartialContent, "234", http.Header{"Content-Range": {"bytes 2-4/10"}}},
+		{"suffix range", true, http.StatusOK, http.Header{"Range": {"bytes=-3"}}, http.StatusPartialContent, "789", http.Header{"Content-Range": {"bytes 7-9/10"}}},
+		{"unsatisfiable range", true, http.StatusOK, http.Header{"Range": {"bytes=20-30"}}, http.StatusRequestedRangeNotSatisfiable, "", http.Header{"Content-Range": {"bytes */10"}}},
+		{"not modified", true, http.StatusOK, http.Header{"If-Modified-Since": {modTime.Format(http.TimeFormat)}}, http.StatusNotModified, "", nil},
+		{"not seekable", false, http.StatusOK, http.Header{"Range": {"bytes=2-4"}}, http.StatusOK, "0123456789", http.Header{"Content-Length": {"10"}, "Last-Modified": {modTime.Format(http.TimeFormat)}}},
+		{"other status", true, http.StatusCreated, http.Header{"Range": {"bytes=2-4"}}, http.StatusCreated, "0123456789", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, recorder := newTestBody("0123456789", tt.seekable)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+			w := httptest.NewRecorder()
+			serveFile(w, r, tt.status, &File{Body: body, ContentType: "text/plain", Size: 10, Name: "data.txt", ModTime: modTime})
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus != http.StatusRequestedRangeNotSatisfiable {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+			for key, values := range tt.wantHeader {
+				assert.Equal(t, values, w.Header()[key], key)
+			}
+			assert.True(t, recorder.closed, "the body was not closed")
+		})
+	}
+}
+
+func Test_fileResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       *File
+		err        error
+		wantStatus int
+	}{
+		{"no file", nil, nil, http.StatusNoContent},
+		{"no body", &File{}, nil, http.StatusInternalServerError},
+		{"error", nil, &HTTPError{http.StatusNotFound, errors.New("no such file")}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			fileResponse(w, httptest.NewRequest(http.MethodGet, "/", nil), tt.file, tt.err)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_authorize(t *testing.T) {
+	// the fake authorizer denies the schemes with an error
+	denied := map[string]error{
+		"anonymous": Unauthorized("unauthenticated", "no credentials"),
+		"reader":    Forbidden("forbidden", "missing scope"),
+		"broken":    errors.New("invalid token"),
+	}
+	authorizer := func(r *http.Request, scheme SecurityScheme, scopes []string) error {
+		return denied[scheme.Name]
+	}
+	schemes := map[string]SecurityScheme{
+		"apiKey": {Name: "apiKey", Type: "apiKey", In: "header", Parameter: "X-API-Key"},
+	}
+
+	tests := []struct {
+		name       string
+		authorizer Authorizer
+		reqs       []SecurityRequirement
+		wantStatus int
+		wantCode   string
+	}{
+		{"allowed", authorizer, []SecurityRequirement{{"apiKey": nil}}, http.StatusOK, ""},
+		{"denied", authorizer, []SecurityRequirement{{"anonymous": nil}}, http.StatusUnauthorized, "unauthenticated"},
+		{"or allowed", authorizer, []SecurityRequirement{{"anonymous": nil}, {"apiKey": nil}}, http.StatusOK, ""},
+		{"or denied with highest status", authorizer, []SecurityRequirement{{"anonymous": nil}, {"reader": nil}}, http.StatusForbidden, "forbidden"},
+		{"and allowed", authorizer, []SecurityRequirement{{"apiKey": nil, "legacy": []string{"read"}}}, http.StatusOK, ""},
+		{"and denied", authorizer, []SecurityRequirement{{"apiKey": nil, "reader": nil}}, http.StatusForbidden, "forbidden"},
+		{"plain error", authorizer, []SecurityRequirement{{"broken": nil}}, http.StatusUnauthorized, ""},
+		{"no authorizer", nil, []SecurityRequirement{{"apiKey": nil}}, http.StatusUnauthorized, "no_authorizer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			w := httptest.NewRecorder()
+			authorize(tt.authorizer, schemes, tt.reqs)(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+			var p Problem
+			if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p)) {
+				assert.Equal(t, tt.wantCode, p.Code)
+			}
+		})
+	}
+}
+
+func Test_authorizeSchemes(t *testing.T) {
+	var got []SecurityScheme
+	authorizer := func(r *http.Request, scheme SecurityScheme, scopes []string) error {
+		got = append(got, scheme)
+		return nil
+	}
+	schemes := map[string]SecurityScheme{
+		"apiKey": {Name: "apiKey", Type: "apiKey", In: "header", Parameter: "X-API-Key"},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	reqs := []SecurityRequirement{{"roles": []string{"read"}, "apiKey": nil}}
+	authorize(authorizer, schemes, reqs)(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// the schemes are checked by name, schemes without definition only have a name
+	assert.Equal(t, []SecurityScheme{schemes["apiKey"], {Name: "roles"}}, got)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateResponses(t *testing.T) {
+	modTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	specs := map[string]*operationSpec{
+		"GET /file": {
+			produces:  []string{"text/plain"},
+			responses: map[string]responseSpec{"200": {file: true}},
+		},
+		"GET /empty": {
+			responses: map[string]responseSpec{"204": {}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		header     http.Header
+		wantStatus int
+		wantBody   string
+	}{
+		{"file", "/file", nil, http.StatusOK, "0123456789"},
+		{"range", "/file", http.Header{"Range": {"bytes=2-4"}}, http.StatusPartialContent, "234"},
+		{"not modified", "/file", http.Header{"If-Modified-Since": {modTime.Format(http.TimeFormat)}}, http.StatusNotModified, ""},
+		{"unsatisfiable range", "/file", http.Header{"Range": {"bytes=20-30"}}, http.StatusRequestedRangeNotSatisfiable, ""},
+		{"unexpected body", "/empty", nil, http.StatusInternalServerError, "invalid_response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			router.Use(validateResponses(specs, true))
+			router.Get("/file", func(w http.ResponseWriter, r *http.Request) {
+				body := struct {
+					io.ReadSeeker
+					io.Closer
+				}{strings.NewReader("0123456789"), io.NopCloser(nil)}
+				fileResponse(w, r, &File{Body: body, ContentType: "text/plain", ModTime: modTime}, nil)
+			})
+			router.Get("/empty", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+				w.Write([]byte("body"))
+			})
+
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func Test_validateResponsesStreamsFiles(t *testing.T) {
+	specs := map[string]*operationSpec{
+		"GET /file": {
+			produces:  []string{"application/octet-stream"},
+			responses: map[string]responseSpec{"200": {file: true}},
+		},
+	}
+
+	w := httptest.NewRecorder()
+	router := chi.NewRouter()
+	router.Use(validateResponses(specs, true))
+	router.Get("/file", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte("chunk"))
+		assert.Equal(t, "chunk", w.Body.String(), "the file was buffered")
+		rw.(http.Flusher).Flush()
+		assert.True(t, w.Flushed)
+	})
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/file", nil))
+}
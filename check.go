@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	stdtime "time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// responseCode matches the status codes of responses, ranges like 4XX are
+// only defined by OpenAPI 3.0.
+var responseCode = regexp.MustCompile(`^([1-5][0-9][0-9]|[1-5]XX|default)$`)
+
+// check returns the problems of a parsed document that would lead to broken
+// generated code.
+func check(swagger *Swagger) SpecErrors {
+	var errs SpecErrors
+
+	visitSchemas(swagger, func(s *Schema) {
+		errs = append(errs, checkSchema(swagger, s)...)
+	})
+	errs = append(errs, checkAllOfCycles(swagger)...)
+
+	errs = append(errs, checkSecurity(swagger, mappingValue(swagger.node, "security"), swagger.Security)...)
+
+	operationIDs := map[string]bool{}
+	for _, lpath := range sortedKeys(swagger.Paths) {
+		for _, operation := range swagger.Paths[lpath].operations() {
+			if operation.OperationID != "" {
+				if operationIDs[operation.OperationID] {
+					errs = append(errs, errorf(operation.node, "duplicate operationId %s", operation.OperationID))
+				}
+				operationIDs[operation.OperationID] = true
+			}
+
+			for _, parameter := range operation.Parameters {
+				errs = append(errs, checkParameter(parameter)...)
+			}
+			errs = append(errs, checkSecurity(swagger, operation.node, operation.Security)...)
+			if operation.MaxBodySize < 0 {
+				errs = append(errs, errorf(operation.node, "invalid x-max-body-size %d", operation.MaxBodySize))
+			}
+
+			for _, code := range sortedKeys(operation.Responses) {
+				if !responseCode.MatchString(code) {
+					errs = append(errs, errorf(operation.node, "invalid response code %s", code))
+				}
+				headers := operation.Responses[code].Headers
+				for _, name := range sortedKeys(headers) {
+					errs = append(errs, checkHeader(operation, code, name, headers[name])...)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// checkSecurity reports security requirements that name undefined security
+// schemes. The roles scheme of older documents needs no definition.
+func checkSecurity(swagger *Swagger, node *yaml.Node, reqs []SecurityRequirement) SpecErrors {
+	var errs SpecErrors
+	for _, req := range reqs {
+		for _, name := range sortedKeys(req) {
+			if _, ok := swagger.SecurityDefinitions[name]; !ok && name != "roles" {
+				errs = append(errs, errorf(node, "unknown security scheme %s", name))
+			}
+		}
+	}
+	return errs
+}
+
+// checkSchema reports the problems of a single schema, nested schemas are
+// checked separately.
+func checkSchema(swagger *Swagger, s *Schema) SpecErrors {
+	var errs SpecErrors
+
+	if s.Ref != "" {
+		if !isDefinition(swagger, s.Ref) {
+			errs = append(errs, errorf(s.node, "unknown reference %s", s.Ref))
+		}
+		return errs
+	}
+
+	switch s.Type {
+	case "", "string", "number", "integer", "boolean", "object", "file":
+	case "array":
+		if s.Items == nil {
+			errs = append(errs, errorf(s.node, "array without items"))
+		}
+	default:
+		errs = append(errs, errorf(s.node, "unknown type %s", s.Type))
+	}
+
+	if s.Default != nil {
+		if message := checkDefault(s, s.Default); message != "" {
+			errs = append(errs, errorf(s.node, "%s", message))
+		}
+	}
+
+	if s.Discriminator != nil {
+		for _, value := range sortedKeys(s.Discriminator.Mapping) {
+			// OpenAPI 3.0 mappings may also use plain schema names
+			ref := s.Discriminator.Mapping[value]
+			if _, ok := swagger.Definitions[path.Base(ref)]; !ok {
+				errs = append(errs, errorf(s.node, "unknown reference %s in discriminator mapping", ref))
+			}
+		}
+	}
+
+	return errs
+}
+
+// checkAllOfCycles reports definitions that embed themselves through their
+// allOf references, which would generate recursive struct types. Each cycle
+// is reported once, at the reference that closes it.
+func checkAllOfCycles(swagger *Swagger) SpecErrors {
+	var errs SpecErrors
+	done := map[string]bool{}
+	var visit func(name string, stack []string)
+	visit = func(name string, stack []string) {
+		for i, parent := range stack {
+			if parent == name {
+				cycle := append(append([]string{}, stack[i:]...), name)
+				ref := allOfRef(swagger.Definitions[stack[len(stack)-1]], name)
+				errs = append(errs, errorf(ref.node, "cyclic allOf %s", strings.Join(cycle, " -> ")))
+				return
+			}
+		}
+		s, ok := swagger.Definitions[name]
+		if !ok || done[name] {
+			return
+		}
+		for _, embedded := range embeds(s) {
+			visit(embedded, append(stack, name))
+		}
+		done[name] = true
+	}
+	for _, name := range sortedKeys(swagger.Definitions) {
+		visit(name, nil)
+	}
+	return errs
+}
+
+// allOfRef returns the allOf reference of the schema to the definition.
+func allOfRef(s *Schema, name string) *Schema {
+	for _, schema := range s.AllOf {
+		if schema.Ref != "" && path.Base(schema.Ref) == name {
+			return schema
+		}
+	}
+	return s
+}
+
+// checkParameter reports the problems of a parameter, its schemas are
+// checked separately.
+func checkParameter(p *Parameter) SpecErrors {
+	var errs SpecErrors
+
+	switch p.In {
+	case "query", "header", "cookie", "formData":
+	case "path":
+		if !p.Required {
+			errs = append(errs, errorf(p.node, "path parameter %s must be required", p.Name))
+		}
+	case "body":
+		if p.Schema == nil {
+			errs = append(errs, errorf(p.node, "body parameter %s without schema", p.Name))
+		}
+		return errs
+	default:
+		errs = append(errs, errorf(p.node, "parameter %s has unknown location %s", p.Name, p.In))
+	}
+
+	if p.Schema != nil {
+		return errs
+	}
+
+	switch p.Type {
+	case "string", "number", "integer", "boolean":
+	case "array":
+		if p.Items == nil {
+			errs = append(errs, errorf(p.node, "array parameter %s without items", p.Name))
+		}
+		if _, ok := separators[p.CollectionFormat]; !ok {
+			errs = append(errs, errorf(p.node, "parameter %s has unknown collectionFormat %s", p.Name, p.CollectionFormat))
+		} else if p.CollectionFormat == "multi" && p.In != "query" && p.In != "formData" {
+			errs = append(errs, errorf(p.node, "collectionFormat multi of parameter %s is only supported in query and formData", p.Name))
+		}
+	case "file":
+		if p.In != "formData" {
+			errs = append(errs, errorf(p.node, "file parameter %s must be in formData", p.Name))
+		}
+	case "":
+		errs = append(errs, errorf(p.node, "parameter %s without type", p.Name))
+	default:
+		errs = append(errs, errorf(p.node, "parameter %s has unknown type %s", p.Name, p.Type))
+	}
+
+	if p.Default != nil {
+		s := parameterSchema(*p)
+		s.Enum = p.Enum
+		if message := checkDefault(s, p.Default); message != "" {
+			errs = append(errs, errorf(p.node, "parameter %s: %s", p.Name, message))
+		}
+	}
+
+	return errs
+}
+
+// checkHeader reports the problems of a response header, which is located
+// at its operation.
+func checkHeader(operation *Operation, code, name string, header *Header) SpecErrors {
+	var errs SpecErrors
+
+	switch header.Type {
+	case "string", "number", "integer", "boolean":
+	case "array":
+		if header.Items == nil {
+			errs = append(errs, errorf(operation.node, "array header %s of response %s without items", name, code))
+		}
+		if _, ok := separators[header.CollectionFormat]; !ok || header.CollectionFormat == "multi" {
+			errs = append(errs, errorf(operation.node, "header %s of response %s has unsupported collectionFormat %s", name, code, header.CollectionFormat))
+		}
+	default:
+		errs = append(errs, errorf(operation.node, "header %s of response %s has unknown type %s", name, code, header.Type))
+	}
+
+	return errs
+}
+
+// checkDefault returns a message if a default value does not match the type,
+// format or enum of the schema. Defaults of references are not checked.
+func checkDefault(s *Schema, def interface{}) string {
+	if s.Ref != "" || s.Type == "" {
+		return ""
+	}
+
+	valid := true
+	switch v := def.(type) {
+	case string:
+		switch {
+		case s.Type != "string":
+			valid = false
+		case s.Format == "date-time":
+			_, err := stdtime.Parse(stdtime.RFC3339, v)
+			valid = err == nil
+		case s.Format == "date":
+			_, err := stdtime.Parse("2006-01-02", v)
+			valid = err == nil
+		}
+	case stdtime.Time:
+		// unquoted timestamps are decoded as time
+		valid = s.Type == "string" && (s.Format == "date-time" || s.Format == "date")
+	case int:
+		valid = s.Type == "integer" || s.Type == "number"
+	case float64:
+		valid = s.Type == "number"
+	case bool:
+		valid = s.Type == "boolean"
+	case map[string]interface{}:
+		valid = s.Type == "object"
+	case []interface{}:
+		if s.Type != "array" {
+			valid = false
+			break
+		}
+		if s.Items != nil {
+			for _, item := range v {
+				if message := checkDefault(s.Items, item); message != "" {
+					return message
+				}
+			}
+		}
+	default:
+		valid = false
+	}
+	if !valid {
+		return fmt.Sprintf("default %v does not match type %s", def, s.Type)
+	}
+
+	if len(s.Enum) > 0 && !containsEnumValue(s.Enum, def) {
+		return fmt.Sprintf("default %v is not one of the enum values %v", def, s.Enum)
+	}
+	return ""
+}
+
+func containsEnumValue(enum []interface{}, v interface{}) bool {
+	for _, value := range enum {
+		if fmt.Sprint(value) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDefinition returns true if ref points to a definition of the document.
+func isDefinition(swagger *Swagger, ref string) bool {
+	if !strings.HasPrefix(ref, "#/definitions/") {
+		return false
+	}
+	_, ok := swagger.Definitions[path.Base(ref)]
+	return ok
+}
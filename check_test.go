@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_check(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "unknown types",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\ndefinitions:\n  User:\n    properties:\n      age: { type: int }\n      tags: { type: array }\n",
+			},
+			want: []string{
+				"swagger.yml:5:12: #/definitions/User/properties/age: unknown type int",
+				"swagger.yml:6:13: #/definitions/User/properties/tags: array without items",
+			},
+		},
+		{
+			name: "parameters",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\npaths:\n  /users/{id}:\n    get:\n      operationId: getUser\n      parameters:\n        - { name: id, in: path, type: string }\n        - { name: limit, in: query, type: int }\n        - { name: user, in: body }\n      responses: { 204: { description: OK } }\n",
+			},
+			want: []string{
+				"swagger.yml:7:11: #/paths/~1users~1{id}/get/parameters/0: path parameter id must be required",
+				"swagger.yml:8:11: #/paths/~1users~1{id}/get/parameters/1: parameter limit has unknown type int",
+				"swagger.yml:9:11: #/paths/~1users~1{id}/get/parameters/2: body parameter user without schema",
+			},
+		},
+		{
+			name: "collection format",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\npaths:\n  /a:\n    get:\n      parameters:\n        - { name: ids, in: query, type: array, collectionFormat: json, items: { type: integer } }\n        - { name: tags, in: header, type: array, collectionFormat: multi, items: { type: string } }\n",
+			},
+			want: []string{
+				"swagger.yml:6:11: #/paths/~1a/get/parameters/0: parameter ids has unknown collectionFormat json",
+				"swagger.yml:7:11: #/paths/~1a/get/parameters/1: collectionFormat multi of parameter tags is only supported in query and formData",
+			},
+		},
+		{
+			name: "defaults",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\npaths:\n  /a:\n    get:\n      parameters:\n        - { name: sort, in: query, type: string, enum: [ asc, desc ], default: up }\ndefinitions:\n  Page:\n    properties:\n      size: { type: integer, default: ten }\n",
+			},
+			want: []string{
+				"swagger.yml:10:13: #/definitions/Page/properties/size: default ten does not match type integer",
+				"swagger.yml:6:11: #/paths/~1a/get/parameters/0: parameter sort: default up is not one of the enum values [asc desc]",
+			},
+		},
+		{
+			name: "allOf cycle",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\ndefinitions:\n  A: { allOf: [ { $ref: '#/definitions/B' } ] }\n  B: { allOf: [ { $ref: '#/definitions/C' } ] }\n  C: { allOf: [ { $ref: '#/definitions/A' } ] }\n  D: { allOf: [ { $ref: '#/definitions/D' } ] }\n  E: { allOf: [ { $ref: '#/definitions/A' } ] }\n",
+			},
+			want: []string{
+				"swagger.yml:5:17: #/definitions/C/allOf/0: cyclic allOf A -> B -> C -> A",
+				"swagger.yml:6:17: #/definitions/D/allOf/0: cyclic allOf D -> D",
+			},
+		},
+		{
+			name: "response codes",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\npaths:\n  /a:\n    get:\n      responses:\n        ok: { description: OK }\n        201: { description: Created }\n",
+			},
+			want: []string{
+				"swagger.yml:5:7: #/paths/~1a/get: invalid response code ok",
+			},
+		},
+		{
+			name: "response headers",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\npaths:\n  /a:\n    get:\n      responses:\n        200:\n          description: OK\n          headers:\n            X-Ids: { type: array, collectionFormat: multi, items: { type: integer } }\n            X-Rate: { type: int }\n",
+			},
+			want: []string{
+				"swagger.yml:5:7: #/paths/~1a/get: header X-Ids of response 200 has unsupported collectionFormat multi",
+				"swagger.yml:5:7: #/paths/~1a/get: header X-Rate of response 200 has unknown type int",
+			},
+		},
+		{
+			name: "max body size",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\npaths:\n  /a:\n    put:\n      x-max-body-size: -1\n      responses: { 204: { description: OK } }\n",
+			},
+			want: []string{
+				"swagger.yml:5:7: #/paths/~1a/put: invalid x-max-body-size -1",
+			},
+		},
+		{
+			name: "security",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\nsecurityDefinitions:\n  oauth2: { type: oauth2, flow: implicit, authorizationUrl: 'https://example.com/auth' }\nsecurity: [ { token: [] } ]\npaths:\n  /a:\n    get:\n      security: [ { oauth2: [ read ] }, { key: [] }, { roles: [ admin ] } ]\n      responses: { 204: { description: OK } }\n",
+			},
+			want: []string{
+				"swagger.yml:4:11: #/security: unknown security scheme token",
+				"swagger.yml:8:7: #/paths/~1a/get: unknown security scheme key",
+			},
+		},
+		{
+			name: "duplicate operation",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\npaths:\n  /a: { get: { operationId: list } }\n  /b: { get: { operationId: list } }\n",
+			},
+			want: []string{
+				"swagger.yml:4:14: #/paths/~1b/get: duplicate operationId list",
+			},
+		},
+		{
+			name: "type error",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\npaths:\n  /a:\n    get:\n      operationId: list\n      parameters:\n        - { name: q, in: query, type: string, required: maybe }\n",
+			},
+			want: []string{
+				"swagger.yml:7:57: #/paths/~1a/get/parameters/0/required: cannot unmarshal !!str `maybe` into bool",
+			},
+		},
+		{
+			name: "external file",
+			files: map[string]string{
+				"swagger.yml":      "swagger: '2.0'\ndefinitions:\n  Group: { properties: { owner: { $ref: 'schemas/user.yml#/User' } } }\n",
+				"schemas/user.yml": "User:\n  properties:\n    name: { type: strin }\n",
+			},
+			want: []string{
+				"schemas/user.yml:3:11: #/definitions/User/properties/name: unknown type strin",
+			},
+		},
+		{
+			name: "unresolved references",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\ndefinitions:\n  Group:\n    properties:\n      owner: { $ref: '#/definitions/User' }\n      admin: { $ref: 'missing.yml' }\n",
+			},
+			want: []string{
+				"swagger.yml:5:22: #/definitions/Group/properties/owner/$ref: invalid reference #/definitions/User: User not found",
+				"swagger.yml:6:22: #/definitions/Group/properties/admin/$ref: invalid reference missing.yml: no such file or directory",
+			},
+		},
+		{
+			name: "unresolved reference and schema problems",
+			files: map[string]string{
+				"swagger.yml": "swagger: '2.0'\ndefinitions:\n  Group:\n    properties:\n      admin: { $ref: 'missing.yml' }\n      name: { type: strin }\n",
+			},
+			want: []string{
+				"swagger.yml:5:22: #/definitions/Group/properties/admin/$ref: invalid reference missing.yml: no such file or directory",
+				"swagger.yml:6:13: #/definitions/Group/properties/name: unknown type strin",
+			},
+		},
+		{
+			name: "openapi 3 conversion",
+			files: map[string]string{
+				"swagger.yml": "openapi: 3.0.3\nservers:\n  - url: 'http://[::1'\npaths:\n  /a:\n    get:\n      operationId: list\n      responses:\n        '200': { $ref: '#/components/responses/Missing' }\n",
+			},
+			want: []string{
+				"swagger.yml:9:24: #/paths/~1a/get/responses/200/$ref: invalid reference #/components/responses/Missing: components not found",
+				"swagger.yml:3:5: #/servers/0: invalid server url \"http://[::1\": parse \"http://[::1\": missing ']' in host",
+			},
+		},
+		{
+			name: "unsupported version",
+			files: map[string]string{
+				"swagger.yml": "openapi: 3.1.0\n",
+			},
+			want: []string{
+				"swagger.yml:1:10: #/openapi: unsupported OpenAPI version 3.1.0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), os.ModePerm); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := generate("example.com/generated", filepath.Join(dir, "swagger.yml"))
+
+			var specErrors SpecErrors
+			if !errors.As(err, &specErrors) {
+				t.Fatalf("generate() error = %v, want SpecErrors", err)
+			}
+
+			var got []string
+			for _, e := range specErrors {
+				file, _ := filepath.Rel(dir, e.File)
+				got = append(got, fmt.Sprintf("%s:%d:%d: %s: %s", filepath.ToSlash(file), e.Line, e.Column, e.Pointer, e.Message))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_checkDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *Schema
+		def  interface{}
+		want string
+	}{
+		{"integer", &Schema{Type: "integer"}, 20, ""},
+		{"number", &Schema{Type: "number"}, 20, ""},
+		{"wrong type", &Schema{Type: "integer"}, "20", "default 20 does not match type integer"},
+		{"date", &Schema{Type: "string", Format: "date"}, "2024-01-01", ""},
+		{"invalid date", &Schema{Type: "string", Format: "date"}, "today", "default today does not match type string"},
+		{"enum", &Schema{Type: "string", Enum: []interface{}{"asc", "desc"}}, "up", "default up is not one of the enum values [asc desc]"},
+		{"array", &Schema{Type: "array", Items: &Schema{Type: "integer"}}, []interface{}{1, "x"}, "default x does not match type integer"},
+		{"untyped", &Schema{}, "x", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, checkDefault(tt.s, tt.def), "checkDefault(%v, %v)", tt.s, tt.def)
+		})
+	}
+}
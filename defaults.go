@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	stdtime "time"
+)
+
+// defaults returns the Go statements that set the default value of the
+// absent struct field m.<name> of the property name with the schema s and
+// apply the defaults of nested values.
+func defaults(name string, s *Schema, required []string) string {
+	g := &defaultsGenerator{}
+	g.value("m."+export(name), s, goType(name, s, required), !contains(required, name), 0)
+	return g.String()
+}
+
+// defaultsItems returns the Go statements that apply the defaults of the
+// items of m, which is of the named array type generated for the schema s.
+func defaultsItems(s *Schema) string {
+	g := &defaultsGenerator{}
+	g.loop("m", s.Items, 0)
+	return g.String()
+}
+
+// bodyDefaults returns the Go statements that apply the defaults of the
+// decoded value of a body parameter.
+func bodyDefaults(parameter Parameter) string {
+	if parameter.In != "body" || parameter.Schema == nil {
+		return ""
+	}
+	g := &defaultsGenerator{}
+	g.value(parameterVar(parameter), parameter.Schema, parameterType(parameter), false, 0)
+	return g.String()
+}
+
+// appliesDefaults returns true if an ApplyDefaults method is generated for
+// the schema, which is the case for objects, unions and arrays that contain
+// values with defaults.
+func appliesDefaults(s *Schema) bool {
+	return definitionDefaults(s, map[*Schema]bool{})
+}
+
+// definitionDefaults returns true if the named type of the schema applies
+// defaults. Definitions that are already visited are skipped, so recursive
+// types terminate.
+func definitionDefaults(s *Schema, visited map[*Schema]bool) bool {
+	if visited[s] {
+		return false
+	}
+	visited[s] = true
+	defer delete(visited, s)
+
+	switch {
+	case isUnion(s):
+		for _, schema := range s.OneOf {
+			for _, ref := range schema.AllOf {
+				if ref.resolved != nil && definitionDefaults(ref.resolved, visited) {
+					return true
+				}
+			}
+		}
+	case isObject(s):
+		for _, schema := range s.AllOf {
+			if schema.resolved != nil && definitionDefaults(schema.resolved, visited) {
+				return true
+			}
+		}
+		reqs := required(s)
+		for name, property := range properties(s) {
+			if containsDefaults(property, goType(name, property, reqs), !contains(reqs, name), visited) {
+				return true
+			}
+		}
+	case s.Type == "array" && s.Items != nil:
+		return containsDefaults(s.Items, schemaType("", "", s.Items, nil, true), false, visited)
+	}
+	return false
+}
+
+// containsDefaults returns true if a value of the Go type typ with the
+// schema s has a default value or contains values with defaults.
+func containsDefaults(s *Schema, typ string, optional bool, visited map[*Schema]bool) bool {
+	if optional {
+		if _, ok := defaultLiteral(s, typ); ok {
+			return true
+		}
+	}
+
+	switch {
+	case s.Ref != "":
+		return s.resolved != nil && definitionDefaults(s.resolved, visited)
+	case s.Type == "array" && s.Items != nil:
+		return containsDefaults(s.Items, schemaType("", "", s.Items, nil, true), false, visited)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return containsDefaults(s.AdditionalProperties, schemaType("", "", s.AdditionalProperties, nil, true), false, visited)
+	}
+	return false
+}
+
+// schemaDefault returns the default value of the schema. References to
+// enums use the default of the enum definition.
+func schemaDefault(s *Schema) interface{} {
+	if s.Default == nil && s.Ref != "" && s.resolved != nil && isEnum(s.resolved) {
+		return s.resolved.Default
+	}
+	return s.Default
+}
+
+// defaultLiteral returns the Go expression of the default value of a value
+// of the Go type typ, the pointer of pointer types is omitted. Only
+// defaults of strings, numbers, booleans, enums and arrays of them are
+// supported.
+func defaultLiteral(s *Schema, typ string) (string, bool) {
+	def := schemaDefault(s)
+	if def == nil {
+		return "", false
+	}
+
+	if strings.HasPrefix(typ, "[]") {
+		items, ok := def.([]interface{})
+		if !ok || s.Items == nil {
+			return "", false
+		}
+		var literals []string
+		for _, item := range items {
+			literal, ok := valueLiteral(s.Items, item)
+			if !ok {
+				return "", false
+			}
+			literals = append(literals, literal)
+		}
+		return typ + "{" + strings.Join(literals, ", ") + "}", true
+	}
+
+	if !strings.HasPrefix(typ, "*") {
+		return "", false
+	}
+	literal, ok := valueLiteral(s, def)
+	if !ok {
+		return "", false
+	}
+	switch typ = strings.TrimPrefix(typ, "*"); typ {
+	case "string", "bool", "int":
+		return literal, true
+	case "int32", "int64", "float32", "float64":
+		return typ + "(" + literal + ")", true
+	}
+	if s.Ref != "" {
+		// enum constant
+		return literal, true
+	}
+	return "", false
+}
+
+// valueLiteral returns the Go literal of a single default value, enum
+// values are referenced by their constant.
+func valueLiteral(s *Schema, v interface{}) (string, bool) {
+	if s.Ref != "" {
+		if s.resolved == nil || !isEnum(s.resolved) {
+			return "", false
+		}
+		name := refName(s)
+		for _, value := range enumValues(name, s.resolved) {
+			if value.Value == strconv.Quote(fmt.Sprint(v)) || value.Value == fmt.Sprint(v) {
+				return value.Name, true
+			}
+		}
+		return "", false
+	}
+
+	switch formatType("", s) {
+	case "string":
+		return strconv.Quote(fmt.Sprint(v)), true
+	case "bool":
+		b, ok := v.(bool)
+		return strconv.FormatBool(b), ok
+	case "int", "int32", "int64":
+		i, ok := v.(int)
+		return strconv.Itoa(i), ok
+	case "float32", "float64":
+		switch n := v.(type) {
+		case int:
+			return strconv.Itoa(n), true
+		case float64:
+			return strconv.FormatFloat(n, 'g', -1, 64), true
+		}
+	}
+	return "", false
+}
+
+// formatDefault returns the string representation of a default value, which
+// is parsed like a sent parameter value.
+func formatDefault(v interface{}, format string) string {
+	if t, ok := v.(stdtime.Time); ok {
+		if format == "date" {
+			return t.Format("2006-01-02")
+		}
+		return t.Format(stdtime.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+type defaultsGenerator struct {
+	strings.Builder
+}
+
+func (g *defaultsGenerator) line(format string, args ...interface{}) {
+	fmt.Fprintf(g, format+"\n", args...)
+}
+
+// value writes the statements that set the default of expr, which is of the
+// Go type typ, if it is optional and absent and that apply the defaults of
+// nested values.
+func (g *defaultsGenerator) value(expr string, s *Schema, typ string, optional bool, depth int) {
+	if optional {
+		if literal, ok := defaultLiteral(s, typ); ok {
+			g.line("if %s == nil {", expr)
+			if strings.HasPrefix(typ, "*") {
+				g.line("value := %s", literal)
+				g.line("%s = &value", expr)
+			} else {
+				g.line("%s = %s", expr, literal)
+			}
+			g.line("}")
+			return
+		}
+	}
+
+	switch {
+	case s.Ref != "":
+		if s.resolved == nil || !appliesDefaults(s.resolved) {
+			return
+		}
+		if isObject(s.resolved) || isUnion(s.resolved) {
+			// the methods of objects and union wrappers handle nil
+			g.line("%s.ApplyDefaults()", expr)
+			return
+		}
+		g.line("if %s != nil {", expr)
+		g.line("%s.ApplyDefaults()", expr)
+		g.line("}")
+	case s.Type == "array" && s.Items != nil:
+		g.loop(expr, s.Items, depth)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		g.loop(expr, s.AdditionalProperties, depth)
+	}
+}
+
+// loop writes a loop that applies the defaults of the items or values of
+// expr.
+func (g *defaultsGenerator) loop(expr string, s *Schema, depth int) {
+	item := fmt.Sprintf("item%d", depth)
+
+	inner := &defaultsGenerator{}
+	inner.value(item, s, schemaType("", "", s, nil, true), false, depth+1)
+	if inner.Len() > 0 {
+		g.line("for _, %s := range %s {", item, expr)
+		g.WriteString(inner.String())
+		g.line("}")
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_defaults(t *testing.T) {
+	status := &Schema{Type: "string", Enum: []interface{}{"open", "done"}, Default: "open"}
+	user := &Schema{Type: "object", Properties: map[string]*Schema{"locale": {Type: "string", Default: "en"}}}
+
+	type args struct {
+		name     string
+		s        *Schema
+		required []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{"no default", args{"name", &Schema{Type: "string"}, nil}, ""},
+		{"string", args{"name", &Schema{Type: "string", Default: "x"}, nil}, "if m.Name == nil {\nvalue := \"x\"\nm.Name = &value\n}\n"},
+		{"required", args{"name", &Schema{Type: "string", Default: "x"}, []string{"name"}}, ""},
+		{"int32", args{"size", &Schema{Type: "integer", Format: "int32", Default: 20}, nil}, "if m.Size == nil {\nvalue := int32(20)\nm.Size = &value\n}\n"},
+		{"float", args{"weight", &Schema{Type: "number", Default: 2}, nil}, "if m.Weight == nil {\nvalue := float64(2)\nm.Weight = &value\n}\n"},
+		{"date-time", args{"at", &Schema{Type: "string", Format: "date-time", Default: "2024-01-01T00:00:00Z"}, nil}, ""},
+		{"array", args{"tags", &Schema{Type: "array", Items: &Schema{Type: "string"}, Default: []interface{}{"a", "b"}}, nil}, "if m.Tags == nil {\nm.Tags = []string{\"a\", \"b\"}\n}\n"},
+		{"enum", args{"status", &Schema{Ref: "#/definitions/Status", resolved: status}, nil}, "if m.Status == nil {\nvalue := StatusOpen\nm.Status = &value\n}\n"},
+		{"ref", args{"user", &Schema{Ref: "#/definitions/User", resolved: user}, nil}, "m.User.ApplyDefaults()\n"},
+		{"ref without defaults", args{"user", &Schema{Ref: "#/definitions/User", resolved: &Schema{Type: "object"}}, nil}, ""},
+		{"array items", args{"users", &Schema{Type: "array", Items: &Schema{Ref: "#/definitions/User", resolved: user}}, nil}, "for _, item0 := range m.Users {\nitem0.ApplyDefaults()\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, defaults(tt.args.name, tt.args.s, tt.args.required), "defaults(%v, %v, %v)", tt.args.name, tt.args.s, tt.args.required)
+		})
+	}
+}
+
+func Test_appliesDefaults(t *testing.T) {
+	node := &Schema{Type: "object", Properties: map[string]*Schema{"name": {Type: "string"}}}
+	node.Properties["children"] = &Schema{Type: "array", Items: &Schema{Ref: "#/definitions/Node", resolved: node}}
+
+	base := &Schema{Type: "object", Properties: map[string]*Schema{"locale": {Type: "string", Default: "en"}}}
+
+	tests := []struct {
+		name string
+		s    *Schema
+		want bool
+	}{
+		{"no defaults", &Schema{Type: "object", Properties: map[string]*Schema{"name": {Type: "string"}}}, false},
+		{"optional default", base, true},
+		{"required default", &Schema{Type: "object", Required: []string{"locale"}, Properties: map[string]*Schema{"locale": {Type: "string", Default: "en"}}}, false},
+		{"embedded", &Schema{AllOf: []*Schema{{Ref: "#/definitions/Base", resolved: base}}}, true},
+		{"recursive", node, false},
+		{"array", &Schema{Type: "array", Items: &Schema{Ref: "#/definitions/Base", resolved: base}}, true},
+		{"enum", &Schema{Type: "string", Enum: []interface{}{"a"}, Default: "a"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, appliesDefaults(tt.s), "appliesDefaults(%v)", tt.s)
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SpecError is a problem of the API description. It is located by the JSON
+// pointer of the node in the bundled document and by the position of the
+// node in its source file.
+type SpecError struct {
+	Pointer string
+	File    string
+	Line    int
+	Column  int
+	Message string
+
+	node *yaml.Node // the node the error is located at by locate
+}
+
+func (e *SpecError) Error() string {
+	var b strings.Builder
+	b.WriteString(displayPath(e.File))
+	if e.Line > 0 {
+		fmt.Fprintf(&b, ":%d", e.Line)
+	}
+	if e.Column > 0 {
+		fmt.Fprintf(&b, ":%d", e.Column)
+	}
+	if e.Pointer != "" {
+		b.WriteString(": " + e.Pointer)
+	}
+	b.WriteString(": " + e.Message)
+	return b.String()
+}
+
+// SpecErrors contains all problems found in an API description.
+type SpecErrors []*SpecError
+
+func (e SpecErrors) Error() string {
+	lines := []string{fmt.Sprintf("%d problems found in the API description:", len(e))}
+	if len(e) == 1 {
+		lines[0] = "1 problem found in the API description:"
+	}
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// displayPath returns filename relative to the working directory if it is
+// inside of it.
+func displayPath(filename string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
+	rel, err := filepath.Rel(wd, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename
+	}
+	return rel
+}
+
+// locator finds the source file, the position and the JSON pointer of the
+// nodes of a bundled document.
+type locator struct {
+	root  string                // file name of the root document
+	doc   *yaml.Node            // bundled document
+	docs  map[string]*yaml.Node // source documents by file name
+	files map[*yaml.Node]string // source file of every node
+}
+
+// errorf returns an error located at node.
+func errorf(node *yaml.Node, format string, args ...interface{}) *SpecError {
+	return &SpecError{Message: fmt.Sprintf(format, args...), node: node}
+}
+
+// asSpecError returns err as SpecError, other errors are located at node.
+func asSpecError(err error, node *yaml.Node) *SpecError {
+	var specError *SpecError
+	if errors.As(err, &specError) {
+		return specError
+	}
+	return errorf(node, "%s", err)
+}
+
+// locate sets the locations of the errors and returns them as SpecErrors or
+// nil if there are no errors.
+func (l *locator) locate(errs SpecErrors) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for _, err := range errs {
+		if err.node == nil {
+			if err.File == "" {
+				err.File = l.root
+			}
+			continue
+		}
+
+		err.File = l.root
+		if file, ok := l.files[err.node]; ok {
+			err.File = file
+		}
+		err.Line, err.Column = err.node.Line, err.node.Column
+
+		pointer, ok := pointerTo(l.doc, err.node)
+		if !ok {
+			// the node was replaced while bundling, so it can only be found
+			// in its source document
+			pointer, _ = pointerTo(l.docs[err.File], err.node)
+		}
+		err.Pointer = "#" + pointer
+	}
+	return errs
+}
+
+// withoutUnresolved removes the problems of schemas and other objects with a
+// reference that could not be resolved, those are already reported by the
+// loader.
+func withoutUnresolved(errs, loadErrs SpecErrors) SpecErrors {
+	unresolved := map[*yaml.Node]bool{}
+	for _, err := range loadErrs {
+		unresolved[err.node] = true
+	}
+
+	var kept SpecErrors
+	for _, err := range errs {
+		if ref := mappingValue(err.node, "$ref"); ref != nil && unresolved[ref] {
+			continue
+		}
+		kept = append(kept, err)
+	}
+	return kept
+}
+
+var (
+	lineError   = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	quotedValue = regexp.MustCompile("`([^`]*)`")
+)
+
+// specErrors returns the SpecErrors of err, type errors of decoding the
+// bundled document are converted to SpecErrors. Other errors are returned
+// unchanged.
+func (l *locator) specErrors(err error) (SpecErrors, error) {
+	var specErrors SpecErrors
+	if err == nil || errors.As(err, &specErrors) {
+		return specErrors, nil
+	}
+
+	var typeError *yaml.TypeError
+	if !errors.As(err, &typeError) {
+		return nil, err
+	}
+
+	var errs SpecErrors
+	for _, message := range typeError.Errors {
+		match := lineError.FindStringSubmatch(message)
+		if match == nil {
+			errs = append(errs, &SpecError{Message: message})
+			continue
+		}
+
+		line, _ := strconv.Atoi(match[1])
+		var value string
+		if quoted := quotedValue.FindStringSubmatch(match[2]); quoted != nil {
+			value = quoted[1]
+		}
+		if node := l.nodeAt(line, value); node != nil {
+			errs = append(errs, errorf(node, "%s", match[2]))
+		} else {
+			errs = append(errs, &SpecError{Line: line, Message: match[2]})
+		}
+	}
+	return errs, nil
+}
+
+// nodeAt returns the node of the bundled document at line, preferring
+// scalars with the value, which is quoted in type errors, and nodes of the
+// root document. The yaml decoder only reports the line of type errors.
+func (l *locator) nodeAt(line int, value string) *yaml.Node {
+	var found *yaml.Node
+	score := func(node *yaml.Node) int {
+		score := 0
+		if node.Kind == yaml.ScalarNode && node.Value == value {
+			score += 2
+		}
+		if l.files[node] == l.root {
+			score++
+		}
+		return score
+	}
+	walkNodes(l.doc, func(node *yaml.Node) bool {
+		if node.Line == line && (found == nil || score(node) > score(found)) {
+			found = node
+		}
+		return true
+	})
+	return found
+}
+
+// walkNodes calls f for node and all of its descendants until f returns
+// false.
+func walkNodes(node *yaml.Node, f func(*yaml.Node) bool) bool {
+	if node == nil || !f(node) {
+		return false
+	}
+	for _, child := range node.Content {
+		if !walkNodes(child, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// pointerTo returns the JSON pointer of target in the document root. Mapping
+// keys are located at the pointer of their value.
+func pointerTo(root, target *yaml.Node) (string, bool) {
+	if root == nil {
+		return "", false
+	}
+	if root == target {
+		return "", true
+	}
+
+	for i, child := range root.Content {
+		var segment string
+		switch root.Kind {
+		case yaml.MappingNode:
+			if i%2 == 0 {
+				if child == target {
+					return "/" + escapePointer(child.Value), true
+				}
+				continue
+			}
+			segment = escapePointer(root.Content[i-1].Value)
+		case yaml.SequenceNode:
+			segment = strconv.Itoa(i)
+		}
+
+		if pointer, ok := pointerTo(child, target); ok {
+			return "/" + segment + pointer, true
+		}
+	}
+	return "", false
+}
+
+func escapePointer(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_pointerTo(t *testing.T) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte("paths:\n  /users/{id}:\n    get:\n      parameters: [ { name: id } ]\n"), doc); err != nil {
+		t.Fatal(err)
+	}
+	root := doc.Content[0]
+	operation := lookupPath(root, []string{"paths", "/users/{id}", "get"})
+	parameter := mappingValue(operation, "parameters").Content[0]
+
+	tests := []struct {
+		name   string
+		target *yaml.Node
+		want   string
+		wantOk bool
+	}{
+		{"root", root, "", true},
+		{"escaped", operation, "/paths/~1users~1{id}/get", true},
+		{"sequence", parameter, "/paths/~1users~1{id}/get/parameters/0", true},
+		{"key", parameter.Content[0], "/paths/~1users~1{id}/get/parameters/0/name", true},
+		{"missing", &yaml.Node{}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pointerTo(root, tt.target)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+func TestSpecError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *SpecError
+		want string
+	}{
+		{"full", &SpecError{Pointer: "#/definitions/User", File: "/api/swagger.yml", Line: 3, Column: 5, Message: "unknown type int"}, "/api/swagger.yml:3:5: #/definitions/User: unknown type int"},
+		{"line only", &SpecError{File: "/api/swagger.yml", Line: 3, Message: "did not find expected key"}, "/api/swagger.yml:3: did not find expected key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.err.Error())
+		})
+	}
+}
@@ -1,29 +1,68 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/iancoleman/strcase"
-	"github.com/tidwall/sjson"
 )
 
 var funcs = map[string]interface{}{
-	"goType":        goType,
-	"parameterType": parameterType,
-	"parameterName": parameterName,
-	"responseType":  responseType,
-	"omitempty":     omitempty,
-	"toJSON":        toJSON,
-	"export":        export,
-	"examplePath":   examplePath,
-	"exampleBody":   exampleBody,
-	"dict":          dict,
-	"roles":         roles,
+	"goType":               goType,
+	"parameterType":        parameterType,
+	"parameterName":        parameterName,
+	"parameterVar":         parameterVar,
+	"parameterParser":      parameterParser,
+	"plainString":          plainString,
+	"separator":            separator,
+	"maxItems":             maxItems,
+	"hasDefault":           hasDefault,
+	"defaultValue":         defaultValue,
+	"defaultItems":         defaultItems,
+	"defaults":             defaults,
+	"defaultsItems":        defaultsItems,
+	"bodyDefaults":         bodyDefaults,
+	"appliesDefaults":      appliesDefaults,
+	"responseType":         responseType,
+	"hasResult":            hasResult,
+	"typedResponses":       typedResponses,
+	"producesBody":         producesBody,
+	"producesFile":         producesFile,
+	"hasOperations":        hasOperations,
+	"isFile":               isFile,
+	"maxBodySize":          maxBodySize,
+	"responses":            responses,
+	"setHeader":            setHeader,
+	"omitempty":            omitempty,
+	"export":               export,
+	"examplePath":          examplePath,
+	"exampleBody":          exampleBody,
+	"dict":                 dict,
+	"securityRequirements": securityRequirements,
+	"isPublic":             isPublic,
+	"isObject":             isObject,
+	"embeds":               embeds,
+	"properties":           properties,
+	"required":             required,
+	"isUnion":              isUnion,
+	"isRawJSON":            isRawJSON,
+	"unionItems":           unionItems,
+	"variants":             variants,
+	"variantField":         variantField,
+	"resolve":              resolve,
+	"refName":              refName,
+	"isEnum":               isEnum,
+	"enumType":             enumType,
+	"enumBaseType":         enumBaseType,
+	"enumValues":           enumValues,
+	"validation":           validation,
+	"validationChecks":     validationChecks,
+	"validates":            validates,
+	"parameterValidation":  parameterValidation,
 }
 
 func goType(name string, s *Schema, required []string) string {
@@ -32,35 +71,49 @@ func goType(name string, s *Schema, required []string) string {
 
 func parameterType(parameter Parameter) string {
 	reqs := []string{parameter.Name}
-	if !parameter.Required {
+	if !parameter.Required && !hasDefault(parameter) {
 		reqs = nil
 	}
 
-	s := parameter.Schema
 	if parameter.In == "formData" && parameter.Type != "file" {
 		return "[]string" // This parameter type can be anything but parsing has to be done as string array
 	}
 
-	if parameter.Type != "" {
-		s = &Schema{
-			Format:      parameter.Format,
-			Description: parameter.Description,
-			Default:     parameter.Default,
-			Maximum:     parameter.Maximum,
-			Items:       parameter.Items,
-			Type:        parameter.Type,
-		}
+	return schemaType("model.", parameter.Name, parameterSchema(parameter), reqs, false)
+}
+
+// parameterSchema returns the schema of a parameter.
+func parameterSchema(parameter Parameter) *Schema {
+	if parameter.Type == "" {
+		return parameter.Schema
 	}
 
-	return schemaType("model.", parameter.Name, s, reqs, false)
+	return &Schema{
+		Format:           parameter.Format,
+		Description:      parameter.Description,
+		Default:          parameter.Default,
+		Minimum:          parameter.Minimum,
+		Maximum:          parameter.Maximum,
+		ExclusiveMinimum: parameter.ExclusiveMinimum,
+		ExclusiveMaximum: parameter.ExclusiveMaximum,
+		MultipleOf:       parameter.MultipleOf,
+		MinLength:        parameter.MinLength,
+		MaxLength:        parameter.MaxLength,
+		Pattern:          parameter.Pattern,
+		MinItems:         parameter.MinItems,
+		MaxItems:         parameter.MaxItems,
+		UniqueItems:      parameter.UniqueItems,
+		Items:            parameter.Items,
+		Type:             parameter.Type,
+	}
 }
 
 func parameterName(parameter Parameter) string {
 	parameterType := parameterType(parameter)
 	prefix := ""
-	if strings.HasPrefix(parameterType, "*model.") {
+	if strings.HasPrefix(strings.TrimPrefix(parameterType, "*"), "model.") {
 		prefix = "model."
-		parameterType = strings.TrimPrefix(parameterType, "*model.")
+		parameterType = strings.TrimPrefix(strings.TrimPrefix(parameterType, "*"), "model.")
 	}
 
 	name := strcase.ToCamel(parameterType)
@@ -71,12 +124,300 @@ func parameterName(parameter Parameter) string {
 	return prefix + strings.Title(name)
 }
 
+// parameterVar returns the name of the Go variable of a parsed parameter.
+func parameterVar(parameter Parameter) string {
+	return strcase.ToLowerCamel(parameter.Name) + "P"
+}
+
+// parsers are the functions of the api package that parse a single
+// parameter value into a Go type.
+var parsers = map[string]string{
+	"string":     "parseString",
+	"int":        "parseInt",
+	"int32":      "parseInt32",
+	"int64":      "parseInt64",
+	"float32":    "parseFloat32",
+	"float64":    "parseFloat64",
+	"bool":       "parseBool",
+	"time.Time":  "parseDateTime",
+	"model.Date": "model.ParseDate",
+	"[]byte":     "parseBytes",
+	"net.IP":     "parseIP",
+}
+
+// parameterParser returns the function that parses a single value of a
+// parameter, or of its items for array parameters.
+func parameterParser(parameter Parameter) string {
+	if name := enumType(parameter); name != "" {
+		return "model.Parse" + name
+	}
+
+	s := parameterSchema(parameter)
+	if s.Type == "array" && s.Items != nil {
+		s = s.Items
+	}
+	if parser, ok := parsers[formatType("model.", s)]; ok {
+		return parser
+	}
+	return "parseString"
+}
+
+// plainString returns true if a path or query parameter is passed as plain
+// string, which is empty if the parameter is missing.
+func plainString(parameter Parameter) bool {
+	return (parameter.In == "path" || parameter.In == "query") && !hasDefault(parameter) && parameterName(parameter) == "String"
+}
+
+// hasDefault returns true if a missing query, header, cookie or form
+// parameter is set to its default value.
+func hasDefault(parameter Parameter) bool {
+	switch parameter.In {
+	case "query", "header", "cookie", "formData":
+		return parameter.Default != nil && parameter.Type != "file"
+	}
+	return false
+}
+
+// defaultValue returns the Go string literal of the default value of a
+// parameter, which is parsed like a sent value.
+func defaultValue(parameter Parameter) string {
+	return strconv.Quote(formatDefault(parameter.Default, parameter.Format))
+}
+
+// defaultItems returns the Go literal of the default items of an array or
+// form parameter as strings.
+func defaultItems(parameter Parameter) string {
+	items, ok := parameter.Default.([]interface{})
+	if !ok {
+		items = []interface{}{parameter.Default}
+	}
+
+	format := parameter.Format
+	if parameter.Items != nil {
+		format = parameter.Items.Format
+	}
+
+	var literals []string
+	for _, item := range items {
+		literals = append(literals, strconv.Quote(formatDefault(item, format)))
+	}
+	return "[]string{" + strings.Join(literals, ", ") + "}"
+}
+
+// separators are the item separators of the collection formats.
+var separators = map[string]string{
+	"":      ",",
+	"csv":   ",",
+	"ssv":   " ",
+	"tsv":   "\t",
+	"pipes": "|",
+	"multi": "",
+}
+
+// separator returns the Go literal of the item separator of an array
+// parameter.
+func separator(parameter Parameter) string {
+	return strconv.Quote(separators[parameter.CollectionFormat])
+}
+
+// defaultMaxItems limits the number of items of array parameters without
+// maxItems.
+const defaultMaxItems = 1000
+
+// maxItems returns the maximum number of items of an array parameter.
+func maxItems(parameter Parameter) int {
+	if parameter.MaxItems != nil {
+		return *parameter.MaxItems
+	}
+	return defaultMaxItems
+}
+
+// responseType returns the Go type of the result of an operation, which is
+// the body of its 200 response. Operations without body return no result.
 func responseType(responses map[string]*Response) string {
+	if !hasResult(responses) {
+		return ""
+	}
 	response := responses["200"]
+	if isFile(response.Schema) {
+		return "*File"
+	}
 
 	return schemaType("model.", "result", response.Schema, []string{"result"}, false)
 }
 
+// hasResult returns true if the 200 response has a body, which is returned
+// by the service as result.
+func hasResult(responses map[string]*Response) bool {
+	response, ok := responses["200"]
+	return ok && response != nil && response.Schema != nil
+}
+
+// typedResponses returns true if the operation declares other responses
+// than 200 and 204 or response headers. Those operations return a response
+// type with a variant for each response instead of the plain 200 result.
+func typedResponses(operation *Operation) bool {
+	for code, response := range operation.Responses {
+		if code != "200" && code != "204" || len(response.Headers) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// producesBody returns true if a response of the operation has a body that
+// is not a file, so the media type of the response is negotiated.
+func producesBody(operation *Operation) bool {
+	if !typedResponses(operation) {
+		response, ok := operation.Responses["200"]
+		return ok && response.Schema != nil && !isFile(response.Schema)
+	}
+	for _, response := range operation.Responses {
+		if response.Schema != nil && !isFile(response.Schema) {
+			return true
+		}
+	}
+	return false
+}
+
+// producesFile returns true if a response of the operation is a file, which
+// is streamed in its own media type.
+func producesFile(operation *Operation) bool {
+	for _, response := range operation.Responses {
+		if isFile(response.Schema) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOperations returns true if a path has an operation with an ID, which
+// is the only kind of operation the server routes.
+func hasOperations(paths map[string]*PathItem) bool {
+	for _, pathItem := range paths {
+		for _, operation := range pathItem.operations() {
+			if operation.OperationID != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isFile returns true if the schema of a response is a file of Swagger 2.0
+// or a binary string of OpenAPI 3.0.
+func isFile(s *Schema) bool {
+	return s != nil && (s.Type == "file" || isBinary(s))
+}
+
+// defaultMaxBodySize limits the size of request bodies that are decoded or
+// parsed as form if the operation sets no x-max-body-size.
+const defaultMaxBodySize = 32 << 20
+
+// maxBodySize returns the maximum size of the request body of an operation
+// in bytes. Streamed binary bodies are only limited by x-max-body-size,
+// operations without body are not limited.
+func maxBodySize(operation *Operation) int64 {
+	if operation.MaxBodySize > 0 {
+		return operation.MaxBodySize
+	}
+	for _, parameter := range operation.Parameters {
+		switch {
+		case parameter.In == "body" && parameterType(*parameter) == "io.Reader":
+			return 0
+		case parameter.In == "body", parameter.In == "formData":
+			return defaultMaxBodySize
+		}
+	}
+	return 0
+}
+
+type response struct {
+	Code    string
+	Name    string // suffix of the Go names of the response
+	Type    string // Go type of the body, empty for responses without body
+	Value   string // Go type a received body is decoded into
+	Status  string // status code, empty if the status is set by the service
+	File    bool   // the body is a streamed file
+	Headers []*responseHeader
+}
+
+type responseHeader struct {
+	Name      string // header name
+	Field     string // Go field of the response type
+	Type      string
+	Separator string // item separator of arrays
+}
+
+// responses returns the responses of an operation sorted by status code,
+// which puts the default response last.
+func responses(operation *Operation) []*response {
+	var rs []*response
+	for _, code := range sortedKeys(operation.Responses) {
+		r := &response{Code: code, Name: responseName(code)}
+		if _, err := strconv.Atoi(code); err == nil {
+			r.Status = code
+		}
+		if s := operation.Responses[code].Schema; isFile(s) {
+			r.Type, r.File = "*File", true
+		} else if s != nil {
+			r.Type = schemaType("model.", "body", s, []string{"body"}, false)
+			r.Value = schemaType("model.", "body", s, []string{"body"}, true)
+		}
+		for _, name := range sortedKeys(operation.Responses[code].Headers) {
+			r.Headers = append(r.Headers, headerField(name, operation.Responses[code].Headers[name]))
+		}
+		rs = append(rs, r)
+	}
+	return rs
+}
+
+// headerField returns the field of a response header. Strings are set if
+// they are not empty, other optional headers are pointers.
+func headerField(name string, header *Header) *responseHeader {
+	s := &Schema{Type: header.Type, Format: header.Format, Items: header.Items, Enum: header.Enum}
+	var reqs []string
+	if header.Required || formatType("", s) == "string" {
+		reqs = []string{name}
+	}
+	separator := separators[header.CollectionFormat]
+	if separator == "" {
+		separator = ","
+	}
+	return &responseHeader{
+		Name:      name,
+		Field:     export(name),
+		Type:      schemaType("model.", name, s, reqs, false),
+		Separator: separator,
+	}
+}
+
+// setHeader returns the Go statements that write a response header of the
+// response r.
+func setHeader(header *responseHeader) string {
+	field := "r." + header.Field
+	switch {
+	case header.Type == "string":
+		return fmt.Sprintf("if %s != \"\" {\nw.Header().Set(%q, %s)\n}", field, header.Name, field)
+	case strings.HasPrefix(header.Type, "*"):
+		return fmt.Sprintf("if %s != nil {\nw.Header().Set(%q, formatValue(*%s))\n}", field, header.Name, field)
+	case strings.HasPrefix(header.Type, "[]") && header.Type != "[]byte":
+		return fmt.Sprintf("if len(%s) > 0 {\nw.Header().Set(%q, formatValues(%s, %q))\n}", field, header.Name, field, header.Separator)
+	case header.Type == "[]byte" || header.Type == "net.IP":
+		return fmt.Sprintf("if %s != nil {\nw.Header().Set(%q, formatValue(%s))\n}", field, header.Name, field)
+	}
+	return fmt.Sprintf("w.Header().Set(%q, formatValue(%s))", header.Name, field)
+}
+
+// responseName returns the suffix of the Go names of the response with the
+// status code, like 201, 4XX or Default.
+func responseName(code string) string {
+	if code == "default" {
+		return "Default"
+	}
+	return strings.ToUpper(code)
+}
+
 func schemaType(pkg, name string, s *Schema, required []string, nopointer bool) string {
 	req := ""
 	if !nopointer && !contains(required, name) {
@@ -84,20 +425,31 @@ func schemaType(pkg, name string, s *Schema, required []string, nopointer bool)
 	}
 
 	if s.Ref != "" {
+		if s.resolved != nil && isUnion(s.resolved) {
+			// outside the model package unions are used as interface,
+			// inside as wrapper that can be decoded from JSON
+			if pkg != "" && !nopointer {
+				return pkg + path.Base(s.Ref)
+			}
+			return "*" + pkg + path.Base(s.Ref) + "Value"
+		}
+		if s.resolved != nil && isEnum(s.resolved) {
+			return req + pkg + path.Base(s.Ref)
+		}
+		if s.resolved != nil && isRawJSON(s.resolved) {
+			return pkg + path.Base(s.Ref)
+		}
 		return "*" + pkg + path.Base(s.Ref)
 	}
 
 	t := ""
 
 	switch s.Type {
-	case "string":
-		if s.Format == "date-time" {
-			t = req + "time.Time"
-		} else {
-			t = req + "string"
+	case "string", "number", "integer", "boolean":
+		t = formatType(pkg, s)
+		if !strings.HasPrefix(t, "[]") && t != "io.Reader" && t != "net.IP" {
+			t = req + t
 		}
-	case "boolean":
-		t = req + "bool"
 	case "file":
 		t = req + "[]*multipart.FileHeader"
 	case "object":
@@ -107,24 +459,259 @@ func schemaType(pkg, name string, s *Schema, required []string, nopointer bool)
 		} else {
 			t = "map[string]interface{}"
 		}
-	case "number", "integer":
-		if s.Format != "" {
-			t = req + s.Format
-		} else {
-			t = req + "int"
-		}
 	case "array":
+		if items := unionItems(s); items != nil && pkg != "" && !nopointer {
+			// the service gets and returns the interface of the union
+			return "[]" + pkg + path.Base(items.Ref)
+		}
 		subType := schemaType(pkg, name, s.Items, required, true)
 		t = "[]" + subType
-	case "":
-		t = "interface{}"
 	default:
-		panic(fmt.Sprintf("%#v", s))
+		// unknown types are reported by check before the generation
+		t = "interface{}"
 	}
 
 	return t
 }
 
+// formats maps the formats of the primitive types to Go types. The empty
+// format is used for unknown formats.
+var formats = map[string]map[string]string{
+	"integer": {
+		"":      "int",
+		"int32": "int32",
+		"int64": "int64",
+	},
+	"number": {
+		"":       "float64",
+		"float":  "float32",
+		"double": "float64",
+		// Go types as formats are still supported for compatibility
+		"float32": "float32",
+		"float64": "float64",
+	},
+	"string": {
+		"":          "string",
+		"date-time": "time.Time",
+		"date":      "Date",
+		"byte":      "[]byte",
+		"binary":    "io.Reader",
+		"uuid":      "string",
+		"uri":       "string",
+		"email":     "string",
+		"password":  "string",
+		"ipv4":      "net.IP",
+		"ipv6":      "net.IP",
+	},
+	"boolean": {
+		"": "bool",
+	},
+}
+
+// formatType returns the Go type of a primitive schema.
+func formatType(pkg string, s *Schema) string {
+	t, ok := formats[s.Type][s.Format]
+	if !ok {
+		t = formats[s.Type][""]
+	}
+	if t == "Date" {
+		t = pkg + t
+	}
+	return t
+}
+
+// knownFormat returns false if the format of a primitive schema is not
+// mapped to a Go type.
+func knownFormat(typ, format string) bool {
+	if _, ok := formats[typ]; !ok || format == "" {
+		return true
+	}
+	_, ok := formats[typ][format]
+	return ok
+}
+
+// isObject returns true if a Go struct is generated for the schema.
+func isObject(s *Schema) bool {
+	if isUnion(s) || isRawJSON(s) {
+		return false
+	}
+	return s.Type == "object" || len(s.AllOf) > 0 || (s.Type == "" && len(s.Properties) > 0)
+}
+
+// isUnion returns true if a Go interface is generated for the schema, which
+// is implemented by the types of the discriminator mapping.
+func isUnion(s *Schema) bool {
+	return s.Discriminator != nil && len(s.Discriminator.Mapping) > 0
+}
+
+// unionItems returns the items of an inline array of unions, or nil if the
+// schema is no such array.
+func unionItems(s *Schema) *Schema {
+	if s == nil || s.Ref != "" || s.Type != "array" || s.Items == nil {
+		return nil
+	}
+	if s.Items.Ref == "" || s.Items.resolved == nil || !isUnion(s.Items.resolved) {
+		return nil
+	}
+	return s.Items
+}
+
+// isRawJSON returns true if the schema is a oneOf or anyOf schema without a
+// discriminator, which is kept as raw JSON.
+func isRawJSON(s *Schema) bool {
+	return !isUnion(s) && (len(s.OneOf) > 0 || len(s.AnyOf) > 0)
+}
+
+// resolve returns the definition a reference points to or the schema itself.
+func resolve(s *Schema) *Schema {
+	if s.resolved != nil {
+		return s.resolved
+	}
+	return s
+}
+
+func refName(s *Schema) string {
+	return path.Base(s.Ref)
+}
+
+// isEnum returns true if a named Go type with constants is generated for
+// the schema.
+func isEnum(s *Schema) bool {
+	if len(s.Enum) == 0 || s.Type == "boolean" {
+		return false
+	}
+	switch formatType("", s) {
+	case "string", "int", "int32", "int64", "float32", "float64":
+		return true
+	}
+	return false
+}
+
+// enumType returns the name of the enum type of a parameter or of its items,
+// or an empty string if the parameter is no enum.
+func enumType(parameter Parameter) string {
+	for _, s := range []*Schema{parameter.Schema, parameter.Items} {
+		if s != nil && s.resolved != nil && isEnum(s.resolved) {
+			return path.Base(s.Ref)
+		}
+	}
+	return ""
+}
+
+// enumBaseType returns the underlying Go type of an enum type.
+func enumBaseType(s *Schema) string {
+	return schemaType("", "", &Schema{Type: s.Type, Format: s.Format}, nil, true)
+}
+
+type enumValue struct {
+	Name  string
+	Value string
+}
+
+// enumValues returns the constant names and Go literals of the enum values.
+func enumValues(name string, s *Schema) []*enumValue {
+	var values []*enumValue
+	taken := map[string]bool{}
+	for _, v := range s.Enum {
+		value := &enumValue{Value: fmt.Sprint(v)}
+
+		var suffix string
+		if s.Type == "string" {
+			value.Value = strconv.Quote(fmt.Sprint(v))
+			suffix = export(fmt.Sprint(v))
+		} else {
+			suffix = strings.NewReplacer("-", "Minus", ".", "Point", "+", "").Replace(fmt.Sprint(v))
+		}
+		if suffix == "" {
+			suffix = "Empty"
+		}
+
+		value.Name = name + suffix
+		for i := 2; taken[value.Name]; i++ {
+			value.Name = name + suffix + strconv.Itoa(i)
+		}
+		taken[value.Name] = true
+
+		values = append(values, value)
+	}
+	return values
+}
+
+// embeds returns the names of the types referenced by allOf, which are
+// embedded into the generated struct.
+func embeds(s *Schema) []string {
+	var names []string
+	for _, schema := range s.AllOf {
+		if schema.Ref != "" {
+			names = append(names, path.Base(schema.Ref))
+		}
+	}
+	return names
+}
+
+// properties returns the properties of the schema merged with the
+// properties of inline allOf schemas.
+func properties(s *Schema) map[string]*Schema {
+	props := map[string]*Schema{}
+	for _, schema := range s.AllOf {
+		if schema.Ref == "" {
+			for name, property := range properties(schema) {
+				props[name] = property
+			}
+		}
+	}
+	for name, property := range s.Properties {
+		props[name] = property
+	}
+	return props
+}
+
+// required returns the required properties of the schema merged with the
+// required properties of inline allOf schemas.
+func required(s *Schema) []string {
+	reqs := append([]string{}, s.Required...)
+	for _, schema := range s.AllOf {
+		if schema.Ref == "" {
+			for _, name := range required(schema) {
+				if !contains(reqs, name) {
+					reqs = append(reqs, name)
+				}
+			}
+		}
+	}
+	return reqs
+}
+
+// field is the Go field of a union variant that holds the discriminator
+// property.
+type field struct {
+	Name    string // promoted from embedded structs
+	Type    string // without pointer
+	Pointer bool
+}
+
+// variantField returns the string field of the variant schema or of the
+// definitions it embeds that holds the discriminator property, or nil if the
+// variant has no such field.
+func variantField(s *Schema, property string) *field {
+	if prop, ok := properties(s)[property]; ok {
+		prop = resolve(prop)
+		if prop.Type != "string" || prop.Format != "" {
+			return nil
+		}
+		typ := goType(property, prop, required(s))
+		return &field{Name: export(property), Type: strings.TrimPrefix(typ, "*"), Pointer: strings.HasPrefix(typ, "*")}
+	}
+	for _, schema := range s.AllOf {
+		if schema.Ref != "" {
+			if f := variantField(resolve(schema), property); f != nil {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
 func omitempty(name string, required []string) bool {
 	return !contains(required, name)
 }
@@ -138,12 +725,6 @@ func contains(required []string, name string) bool {
 	return false
 }
 
-func toJSON(name string, i Schema) string {
-	b, _ := json.Marshal(i)
-	b, _ = sjson.SetBytes(b, "$id", "#/definitions/"+name)
-	return string(b)
-}
-
 func export(s string) string {
 	if s == "id" {
 		return "ID"
@@ -196,9 +777,26 @@ func dict(values ...interface{}) (map[string]interface{}, error) {
 	return dict, nil
 }
 
-func roles(reqs []*Security) string {
+// securityRequirements returns the Go literal of the security requirements
+// of an operation.
+func securityRequirements(reqs []SecurityRequirement) string {
+	var literals []string
 	for _, req := range reqs {
-		return strings.Join(req.Roles, ", ")
+		var schemes []string
+		for _, name := range sortedKeys(req) {
+			scopes := "nil"
+			if len(req[name]) > 0 {
+				scopes = fmt.Sprintf("%#v", req[name])
+			}
+			schemes = append(schemes, strconv.Quote(name)+": "+scopes)
+		}
+		literals = append(literals, "{"+strings.Join(schemes, ", ")+"}")
 	}
-	return ""
+	return "[]SecurityRequirement{" + strings.Join(literals, ", ") + "}"
+}
+
+// isPublic returns true if the operation has an empty list of security
+// requirements, so its requests are not authenticated.
+func isPublic(operation *Operation) bool {
+	return operation.Security != nil && len(operation.Security) == 0
 }
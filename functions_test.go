@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	stdtime "time"
 )
 
 func Test_exampleBody(t *testing.T) {
@@ -202,6 +203,8 @@ func Test_responseType(t *testing.T) {
 		want string
 	}{
 		{"simple", args{map[string]*Response{"200": {Schema: &Schema{Type: "string"}}}}, "string"},
+		{"no body", args{map[string]*Response{"200": {}}}, ""},
+		{"no 200", args{map[string]*Response{"204": {}}}, ""},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -210,26 +213,147 @@ func Test_responseType(t *testing.T) {
 	}
 }
 
-func Test_roles(t *testing.T) {
-	type args struct {
-		reqs []*Security
+func Test_typedResponses(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]*Response
+		want      bool
+	}{
+		{"result", map[string]*Response{"200": {}, "204": {}}, false},
+		{"created", map[string]*Response{"201": {}}, true},
+		{"not found", map[string]*Response{"200": {}, "404": {}}, true},
+		{"default", map[string]*Response{"200": {}, "default": {}}, true},
+		{"headers", map[string]*Response{"200": {Headers: map[string]*Header{"ETag": {Type: "string"}}}}, true},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, typedResponses(&Operation{Responses: tt.responses}), "typedResponses(%v)", tt.responses)
+		})
+	}
+}
+
+func Test_responses(t *testing.T) {
+	user := &Schema{Ref: "#/definitions/User", resolved: &Schema{Type: "object"}}
+	operation := &Operation{Responses: map[string]*Response{
+		"default": {Description: "Error", Schema: &Schema{Ref: "#/definitions/Error", resolved: &Schema{Type: "object"}}},
+		"201":     {Description: "Created", Schema: user},
+		"4XX":     {Description: "Client error"},
+		"404":     {Description: "Not found"},
+		"200":     {Description: "OK", Schema: &Schema{Type: "file"}},
+	}}
+
+	want := []*response{
+		{Code: "200", Name: "200", Type: "*File", Status: "200", File: true},
+		{Code: "201", Name: "201", Type: "*model.User", Value: "*model.User", Status: "201"},
+		{Code: "404", Name: "404", Status: "404"},
+		{Code: "4XX", Name: "4XX"},
+		{Code: "default", Name: "Default", Type: "*model.Error", Value: "*model.Error"},
+	}
+	assert.Equal(t, want, responses(operation))
+}
+
+func Test_producesBody(t *testing.T) {
+	file := &Schema{Type: "file"}
+	user := &Schema{Ref: "#/definitions/User", resolved: &Schema{Type: "object"}}
+	tests := []struct {
+		name      string
+		responses map[string]*Response
+		wantBody  bool
+		wantFile  bool
+	}{
+		{"result", map[string]*Response{"200": {Schema: user}}, true, false},
+		{"no content", map[string]*Response{"204": {}}, false, false},
+		{"file", map[string]*Response{"200": {Schema: file}}, false, true},
+		{"binary", map[string]*Response{"200": {Schema: &Schema{Type: "string", Format: "binary"}}}, false, true},
+		{"file or error", map[string]*Response{"200": {Schema: file}, "404": {Schema: user}}, true, true},
+		{"typed without body", map[string]*Response{"201": {}}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation := &Operation{Responses: tt.responses}
+			assert.Equalf(t, tt.wantBody, producesBody(operation), "producesBody(%v)", tt.responses)
+			assert.Equalf(t, tt.wantFile, producesFile(operation), "producesFile(%v)", tt.responses)
+		})
+	}
+}
+
+func Test_maxBodySize(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation *Operation
+		want      int64
+	}{
+		{"no body", &Operation{Parameters: []*Parameter{{Name: "id", In: "path", Type: "string"}}}, 0},
+		{"body", &Operation{Parameters: []*Parameter{{Name: "user", In: "body", Schema: &Schema{Type: "object"}}}}, defaultMaxBodySize},
+		{"form", &Operation{Parameters: []*Parameter{{Name: "file", In: "formData", Type: "file"}}}, defaultMaxBodySize},
+		{"binary body", &Operation{Parameters: []*Parameter{{Name: "content", In: "body", Schema: &Schema{Type: "string", Format: "binary"}}}}, 0},
+		{"extension", &Operation{MaxBodySize: 1024, Parameters: []*Parameter{{Name: "content", In: "body", Schema: &Schema{Type: "string", Format: "binary"}}}}, 1024},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, maxBodySize(tt.operation), "maxBodySize(%v)", tt.operation)
+		})
+	}
+}
+
+func Test_setHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header *Header
+		want   string
+	}{
+		{"string", &Header{Type: "string"}, "if r.Location != \"\" {\nw.Header().Set(\"Location\", r.Location)\n}"},
+		{"optional integer", &Header{Type: "integer"}, "if r.Location != nil {\nw.Header().Set(\"Location\", formatValue(*r.Location))\n}"},
+		{"required integer", &Header{Type: "integer", Required: true}, "w.Header().Set(\"Location\", formatValue(r.Location))"},
+		{"array", &Header{Type: "array", CollectionFormat: "pipes", Items: &Schema{Type: "string"}}, "if len(r.Location) > 0 {\nw.Header().Set(\"Location\", formatValues(r.Location, \"|\"))\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, setHeader(headerField("Location", tt.header)))
+		})
+	}
+}
+
+func Test_securityRequirements(t *testing.T) {
 	tests := []struct {
 		name string
-		args args
+		reqs []SecurityRequirement
 		want string
 	}{
-		{"simple", args{[]*Security{{Roles: []string{"admin"}}}}, "admin"},
-		{"list", args{[]*Security{{Roles: []string{"user", "foo"}}}}, "user, foo"},
+		{"roles", []SecurityRequirement{{"roles": {"admin"}}}, `[]SecurityRequirement{{"roles": []string{"admin"}}}`},
+		{"or", []SecurityRequirement{{"oauth2": {"read", "write"}}, {"apiKey": {}}}, `[]SecurityRequirement{{"oauth2": []string{"read", "write"}}, {"apiKey": nil}}`},
+		{"and", []SecurityRequirement{{"oauth2": {"read"}, "apiKey": nil}}, `[]SecurityRequirement{{"apiKey": nil, "oauth2": []string{"read"}}}`},
+		{"anonymous", []SecurityRequirement{{"apiKey": nil}, {}}, `[]SecurityRequirement{{"apiKey": nil}, {}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, securityRequirements(tt.reqs), "securityRequirements(%v)", tt.reqs)
+		})
+	}
+}
+
+func Test_isPublic(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation *Operation
+		want      bool
+	}{
+		{"empty list", &Operation{Security: []SecurityRequirement{}}, true},
+		{"no requirements", &Operation{}, false},
+		{"anonymous", &Operation{Security: []SecurityRequirement{{}}}, false},
+		{"requirements", &Operation{Security: []SecurityRequirement{{"apiKey": nil}}}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, roles(tt.args.reqs), "roles(%v)", tt.args.reqs)
+			assert.Equal(t, tt.want, isPublic(tt.operation))
 		})
 	}
 }
 
 func Test_schemaType(t *testing.T) {
+	role := &Schema{Type: "string", Enum: []interface{}{"admin", "user"}}
+	pet := &Schema{Discriminator: &Discriminator{PropertyName: "petType", Mapping: map[string]string{"cat": "#/definitions/Cat"}}}
+
 	type args struct {
 		pkg       string
 		name      string
@@ -244,6 +368,20 @@ func Test_schemaType(t *testing.T) {
 	}{
 		{"string", args{"model.", "result", &Schema{Type: "string"}, []string{"result"}, false}, "string"},
 		{"custom", args{"model.", "result", &Schema{Ref: "#/definitions/User"}, []string{"result"}, false}, "*model.User"},
+		{"union", args{"model.", "result", &Schema{Ref: "#/definitions/Pet", resolved: pet}, []string{"result"}, false}, "model.Pet"},
+		{"union item", args{"model.", "result", &Schema{Ref: "#/definitions/Pet", resolved: pet}, []string{"result"}, true}, "*model.PetValue"},
+		{"union field", args{"", "pet", &Schema{Ref: "#/definitions/Pet", resolved: pet}, []string{"pet"}, false}, "*PetValue"},
+		{"enum", args{"", "role", &Schema{Ref: "#/definitions/UserRole", resolved: role}, []string{"role"}, false}, "UserRole"},
+		{"optional enum", args{"model.", "role", &Schema{Ref: "#/definitions/UserRole", resolved: role}, nil, false}, "*model.UserRole"},
+		{"raw json", args{"", "label", &Schema{Ref: "#/definitions/Label", resolved: &Schema{OneOf: []*Schema{{Type: "string"}}}}, nil, false}, "Label"},
+		{"number", args{"", "weight", &Schema{Type: "number"}, []string{"weight"}, false}, "float64"},
+		{"float", args{"", "load", &Schema{Type: "number", Format: "float"}, nil, false}, "*float32"},
+		{"int32", args{"", "port", &Schema{Type: "integer", Format: "int32"}, []string{"port"}, false}, "int32"},
+		{"date", args{"model.", "day", &Schema{Type: "string", Format: "date"}, nil, false}, "*model.Date"},
+		{"byte", args{"", "key", &Schema{Type: "string", Format: "byte"}, nil, false}, "[]byte"},
+		{"binary", args{"model.", "file", &Schema{Type: "string", Format: "binary"}, []string{"file"}, false}, "io.Reader"},
+		{"ipv6", args{"", "ip", &Schema{Type: "string", Format: "ipv6"}, nil, false}, "net.IP"},
+		{"unknown format", args{"", "rssi", &Schema{Type: "integer", Format: "int8"}, []string{"rssi"}, false}, "int"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -253,21 +391,253 @@ func Test_schemaType(t *testing.T) {
 	}
 }
 
-func Test_toJSON(t *testing.T) {
+func Test_isObject(t *testing.T) {
 	type args struct {
+		s *Schema
+	}
+	tests := []struct {
 		name string
-		i    Schema
+		args args
+		want bool
+	}{
+		{"object", args{&Schema{Type: "object"}}, true},
+		{"allOf", args{&Schema{AllOf: []*Schema{{Ref: "#/definitions/User"}}}}, true},
+		{"untyped properties", args{&Schema{Properties: map[string]*Schema{"name": {Type: "string"}}}}, true},
+		{"array", args{&Schema{Type: "array", Items: &Schema{Type: "string"}}}, false},
+		{"union", args{&Schema{Type: "object", OneOf: []*Schema{{Ref: "#/definitions/Cat"}}, Discriminator: &Discriminator{PropertyName: "petType", Mapping: map[string]string{"cat": "#/definitions/Cat"}}}}, false},
+		{"oneOf", args{&Schema{Type: "object", OneOf: []*Schema{{Ref: "#/definitions/Cat"}}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, isObject(tt.args.s), "isObject(%v)", tt.args.s)
+		})
+	}
+}
+
+func Test_isUnion(t *testing.T) {
+	type args struct {
+		s *Schema
 	}
 	tests := []struct {
 		name string
 		args args
-		want string
+		want bool
 	}{
-		{"simple", args{"Name", Schema{Type: "string"}}, "{\"type\":\"string\",\"$id\":\"#/definitions/Name\"}"},
+		{"object", args{&Schema{Type: "object"}}, false},
+		{"discriminator without mapping", args{&Schema{Type: "object", Discriminator: &Discriminator{PropertyName: "petType"}}}, false},
+		{"discriminator", args{&Schema{Discriminator: &Discriminator{PropertyName: "petType", Mapping: map[string]string{"cat": "#/definitions/Cat"}}}}, true},
+		{"oneOf", args{&Schema{OneOf: []*Schema{{Ref: "#/definitions/Cat"}}}}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, toJSON(tt.args.name, tt.args.i), "tojson(%v, %v)", tt.args.name, tt.args.i)
+			assert.Equalf(t, tt.want, isUnion(tt.args.s), "isUnion(%v)", tt.args.s)
 		})
 	}
 }
+
+func Test_embeds(t *testing.T) {
+	type args struct {
+		s *Schema
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{"none", args{&Schema{Type: "object"}}, nil},
+		{"refs", args{&Schema{AllOf: []*Schema{{Ref: "#/definitions/Base"}, {Properties: map[string]*Schema{"name": {Type: "string"}}}, {Ref: "#/definitions/User"}}}}, []string{"Base", "User"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, embeds(tt.args.s), "embeds(%v)", tt.args.s)
+		})
+	}
+}
+
+func Test_properties(t *testing.T) {
+	type args struct {
+		s *Schema
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[string]*Schema
+	}{
+		{"properties", args{&Schema{Properties: map[string]*Schema{"name": {Type: "string"}}}}, map[string]*Schema{"name": {Type: "string"}}},
+		{"allOf", args{&Schema{AllOf: []*Schema{{Ref: "#/definitions/Base"}, {Properties: map[string]*Schema{"name": {Type: "string"}}}}, Properties: map[string]*Schema{"id": {Type: "string"}}}}, map[string]*Schema{"id": {Type: "string"}, "name": {Type: "string"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, properties(tt.args.s), "properties(%v)", tt.args.s)
+		})
+	}
+}
+
+func Test_required(t *testing.T) {
+	type args struct {
+		s *Schema
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{"required", args{&Schema{Required: []string{"name"}}}, []string{"name"}},
+		{"allOf", args{&Schema{AllOf: []*Schema{{Ref: "#/definitions/Base"}, {Required: []string{"name", "id"}}}, Required: []string{"id"}}}, []string{"id", "name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, required(tt.args.s), "required(%v)", tt.args.s)
+		})
+	}
+}
+
+func Test_isEnum(t *testing.T) {
+	type args struct {
+		s *Schema
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"string", args{&Schema{Type: "string"}}, false},
+		{"string enum", args{&Schema{Type: "string", Enum: []interface{}{"admin"}}}, true},
+		{"integer enum", args{&Schema{Type: "integer", Enum: []interface{}{1, 2}}}, true},
+		{"object enum", args{&Schema{Type: "object", Enum: []interface{}{map[string]interface{}{}}}}, false},
+		{"date enum", args{&Schema{Type: "string", Format: "date", Enum: []interface{}{"2020-01-01"}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, isEnum(tt.args.s), "isEnum(%v)", tt.args.s)
+		})
+	}
+}
+
+func Test_enumValues(t *testing.T) {
+	type args struct {
+		name string
+		s    *Schema
+	}
+	tests := []struct {
+		name string
+		args args
+		want []*enumValue
+	}{
+		{"string", args{"UserRole", &Schema{Type: "string", Enum: []interface{}{"admin", "read-only"}}}, []*enumValue{{"UserRoleAdmin", `"admin"`}, {"UserRoleReadOnly", `"read-only"`}}},
+		{"integer", args{"Priority", &Schema{Type: "integer", Enum: []interface{}{1, -1}}}, []*enumValue{{"Priority1", "1"}, {"PriorityMinus1", "-1"}}},
+		{"number", args{"Weight", &Schema{Type: "number", Enum: []interface{}{0.5}}}, []*enumValue{{"Weight0Point5", "0.5"}}},
+		{"collision", args{"Case", &Schema{Type: "string", Enum: []interface{}{"a", "A", ""}}}, []*enumValue{{"CaseA", `"a"`}, {"CaseA2", `"A"`}, {"CaseEmpty", `""`}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, enumValues(tt.args.name, tt.args.s), "enumValues(%v, %v)", tt.args.name, tt.args.s)
+		})
+	}
+}
+
+func Test_knownFormat(t *testing.T) {
+	type args struct {
+		typ    string
+		format string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"no format", args{"string", ""}, true},
+		{"known", args{"integer", "int64"}, true},
+		{"unknown", args{"string", "hex-color"}, false},
+		{"object", args{"object", "custom"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, knownFormat(tt.args.typ, tt.args.format), "knownFormat(%v, %v)", tt.args.typ, tt.args.format)
+		})
+	}
+}
+
+func Test_parameterVar(t *testing.T) {
+	tests := []struct {
+		name      string
+		parameter Parameter
+		want      string
+	}{
+		{"simple", Parameter{Name: "id"}, "idP"},
+		{"header", Parameter{Name: "X-Request-ID"}, "xRequestIdP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, parameterVar(tt.parameter), "parameterVar(%v)", tt.parameter)
+		})
+	}
+}
+
+func Test_parameterParser(t *testing.T) {
+	mode := &Schema{Type: "string", Enum: []interface{}{"full"}}
+
+	tests := []struct {
+		name      string
+		parameter Parameter
+		want      string
+	}{
+		{"string", Parameter{Name: "q", In: "header", Type: "string"}, "parseString"},
+		{"int32", Parameter{Name: "size", In: "header", Type: "integer", Format: "int32"}, "parseInt32"},
+		{"date", Parameter{Name: "day", In: "cookie", Type: "string", Format: "date"}, "model.ParseDate"},
+		{"array", Parameter{Name: "ids", In: "header", Type: "array", Items: &Schema{Type: "integer", Format: "int64"}}, "parseInt64"},
+		{"enum", Parameter{Name: "mode", In: "header", Schema: &Schema{Ref: "#/definitions/Mode", resolved: mode}}, "model.ParseMode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, parameterParser(tt.parameter), "parameterParser(%v)", tt.parameter)
+		})
+	}
+}
+
+func Test_separator(t *testing.T) {
+	tests := []struct {
+		name      string
+		parameter Parameter
+		want      string
+	}{
+		{"default", Parameter{Type: "array"}, `","`},
+		{"tsv", Parameter{Type: "array", CollectionFormat: "tsv"}, `"\t"`},
+		{"multi", Parameter{Type: "array", CollectionFormat: "multi"}, `""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, separator(tt.parameter), "separator(%v)", tt.parameter)
+		})
+	}
+}
+
+func Test_maxItems(t *testing.T) {
+	three := 3
+	assert.Equal(t, 1000, maxItems(Parameter{Type: "array"}))
+	assert.Equal(t, 3, maxItems(Parameter{Type: "array", MaxItems: &three}))
+}
+
+func Test_hasDefault(t *testing.T) {
+	assert.True(t, hasDefault(Parameter{Name: "limit", In: "query", Type: "integer", Default: 20}))
+	assert.True(t, hasDefault(Parameter{Name: "format", In: "formData", Type: "string", Default: "csv"}))
+	assert.False(t, hasDefault(Parameter{Name: "limit", In: "query", Type: "integer"}))
+	assert.False(t, hasDefault(Parameter{Name: "id", In: "path", Required: true, Type: "integer", Default: 1}))
+}
+
+func Test_parameterDefaults(t *testing.T) {
+	limit := Parameter{Name: "limit", In: "query", Type: "integer", Default: 20}
+	assert.Equal(t, "int", parameterType(limit))
+	assert.Equal(t, `"20"`, defaultValue(limit))
+
+	q := Parameter{Name: "q", In: "query", Type: "string", Default: "*"}
+	assert.False(t, plainString(q))
+	assert.Equal(t, "string", parameterType(q))
+
+	since := Parameter{Name: "since", In: "query", Type: "string", Format: "date", Default: stdtime.Date(2024, 1, 2, 0, 0, 0, 0, stdtime.UTC)}
+	assert.Equal(t, `"2024-01-02"`, defaultValue(since))
+
+	states := Parameter{Name: "states", In: "query", Type: "array", Items: &Schema{Type: "string"}, Default: []interface{}{"open", "done"}}
+	assert.Equal(t, `[]string{"open", "done"}`, defaultItems(states))
+	assert.Equal(t, `[]string{"csv"}`, defaultItems(Parameter{Name: "format", In: "formData", Type: "string", Default: "csv"}))
+}
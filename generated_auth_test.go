@@ -0,0 +1,743 @@
+package main_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/cugu/swagger-go-chi/testdata/security/generated/api"
+	"github.com/cugu/swagger-go-chi/testdata/security/generated/auth"
+	"github.com/cugu/swagger-go-chi/testdata/security/generated/model"
+)
+
+// The tests in this file run the generated packages of testdata/security.
+
+type securityService struct{}
+
+func (securityService) Health(context.Context) error { return nil }
+
+func (securityService) ListTickets(context.Context) ([]*model.Ticket, error) {
+	return []*model.Ticket{}, nil
+}
+
+func (securityService) CreateTicket(_ context.Context, ticket *model.Ticket) (api.CreateTicketResponse, error) {
+	return api.CreateTicketResponse201(ticket), nil
+}
+
+// withUser returns a middleware that logs in a user with the realm roles.
+func withUser(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if roles != nil {
+				claims := &auth.Claims{Sub: "user"}
+				claims.RealmAccess.Roles = roles
+				r = r.WithContext(context.WithValue(r.Context(), auth.UserContext, claims))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func Test_generatedAuthorize(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   []string
+		scheme  api.SecurityScheme
+		scopes  []string
+		wantErr string
+	}{
+		{"oauth2", []string{"read"}, api.SecurityScheme{Name: "oauth2", Type: "oauth2"}, []string{"read"}, ""},
+		{"oauth2 without scopes", []string{}, api.SecurityScheme{Name: "oauth2", Type: "oauth2"}, nil, ""},
+		{"openIdConnect", []string{"read"}, api.SecurityScheme{Name: "oidc", Type: "openIdConnect"}, []string{"read"}, ""},
+		{"all scopes", []string{"read"}, api.SecurityScheme{Name: "oauth2", Type: "oauth2"}, []string{"read", "write"}, "Forbidden: the user misses required roles"},
+		{"no user", nil, api.SecurityScheme{Name: "oauth2", Type: "oauth2"}, []string{"read"}, "Unauthorized: no user in context"},
+		{"legacy roles", []string{"b"}, api.SecurityScheme{Name: "roles"}, []string{"a", "b"}, ""},
+		{"legacy roles missing", []string{"c"}, api.SecurityScheme{Name: "roles"}, []string{"a", "b"}, "Forbidden: the user has none of the required roles"},
+		{"api key", []string{"read"}, api.SecurityScheme{Name: "apiKey", Type: "apiKey", In: "header", Parameter: "X-API-Key"}, nil, "Unauthorized: the security scheme apiKey cannot be checked"},
+		{"basic", []string{"read"}, api.SecurityScheme{Name: "basic", Type: "basic"}, nil, "Unauthorized: the security scheme basic cannot be checked"},
+		{"undefined", []string{"read"}, api.SecurityScheme{Name: "token"}, nil, "Unauthorized: the security scheme token cannot be checked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			withUser(tt.roles...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				err := auth.Authorize(auth.DefaultRoleClaims...)(r, tt.scheme, tt.scopes)
+				if tt.wantErr == "" {
+					assert.NoError(t, err)
+				} else {
+					assert.EqualError(t, err, tt.wantErr)
+				}
+			})).ServeHTTP(httptest.NewRecorder(), r)
+		})
+	}
+}
+
+func Test_generatedSecurityRequirements(t *testing.T) {
+	tests := []struct {
+		name       string
+		roles      []string
+		method     string
+		path       string
+		header     http.Header
+		wantStatus int
+	}{
+		{"read", []string{"read"}, http.MethodGet, "/tickets", nil, http.StatusOK},
+		{"missing scope", []string{"write"}, http.MethodGet, "/tickets", nil, http.StatusForbidden},
+		{"anonymous", nil, http.MethodGet, "/tickets", nil, http.StatusUnauthorized},
+		// the apiKey scheme cannot be checked by auth.Authorize, so neither
+		// alternative of createTicket is satisfied
+		{"api key alternative", []string{"read"}, http.MethodPost, "/tickets", nil, http.StatusUnauthorized},
+		{"api key alternative with key", []string{"read"}, http.MethodPost, "/tickets", http.Header{"X-Api-Key": {"key"}}, http.StatusUnauthorized},
+		{"api key and scope", []string{"write"}, http.MethodPost, "/tickets", nil, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := api.NewServer(securityService{}, withUser(tt.roles...), auth.Authorize(auth.DefaultRoleClaims...), nil)
+
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"name":"ticket"}`))
+			r.Header.Set("Content-Type", "application/json")
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func Test_generatedPublicOperations(t *testing.T) {
+	cookies, err := auth.NewCookies(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions := auth.NewSessions(auth.NewMemoryStore(), cookies)
+	oauth2Config := oauth2.Config{ClientID: "app", Endpoint: oauth2.Endpoint{AuthURL: "https://idp.example.com/auth"}}
+	authenticate := auth.Required(sessions, "https://idp.example.com", oauth2Config, nil)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"public", "/health", http.StatusNoContent},
+		{"login", "/tickets", http.StatusFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := api.NewServer(securityService{}, authenticate, auth.Authorize(auth.DefaultRoleClaims...), nil)
+
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func Test_generatedSessionCookie(t *testing.T) {
+	tests := []struct {
+		name            string
+		absoluteTimeout time.Duration
+		wantMaxAge      int
+	}{
+		{"absolute timeout", time.Hour, 3600},
+		{"session cookie", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cookies, err := auth.NewCookies(make([]byte, 32))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessions := auth.NewSessions(auth.NewMemoryStore(), cookies)
+			sessions.AbsoluteTimeout = tt.absoluteTimeout
+
+			w := httptest.NewRecorder()
+			if _, err := sessions.Create(w, httptest.NewRequest(http.MethodGet, "/", nil), &auth.Claims{Sub: "user"}, nil); err != nil {
+				t.Fatal(err)
+			}
+			cookie := w.Result().Cookies()[0]
+			assert.Equal(t, tt.wantMaxAge, cookie.MaxAge)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(cookie)
+			session, err := sessions.Load(r)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "user", session.Subject)
+			}
+		})
+	}
+}
+
+// fixedClock is a clock for the generated time.Clock interface.
+type fixedClock struct{ now time.Time }
+
+func (c *fixedClock) Now() time.Time { return c.now }
+
+// countingStore counts the saves of a session store.
+type countingStore struct {
+	auth.SessionStore
+	saves int
+}
+
+func (c *countingStore) Save(ctx context.Context, session *auth.Session) error {
+	c.saves++
+	return c.SessionStore.Save(ctx, session)
+}
+
+// sessionStores returns the session stores to test.
+func sessionStores(t *testing.T) map[string]auth.SessionStore {
+	fileStore, err := auth.NewFileStore(filepath.Join(t.TempDir(), "sessions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]auth.SessionStore{"memory": auth.NewMemoryStore(), "file": fileStore}
+}
+
+// newSessions returns sessions in the store with an idle timeout of an hour
+// and a clock that can be moved.
+func newSessions(t *testing.T, store auth.SessionStore) (*auth.Sessions, *fixedClock) {
+	cookies, err := auth.NewCookies(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fixedClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	cookies.Clock = clock
+	sessions := auth.NewSessions(store, cookies)
+	sessions.Clock = clock
+	sessions.IdleTimeout = time.Hour
+	return sessions, clock
+}
+
+// login creates a session for the subject and returns its cookie.
+func login(t *testing.T, sessions *auth.Sessions, subject string) (*auth.Session, *http.Cookie) {
+	w := httptest.NewRecorder()
+	session, err := sessions.Create(w, httptest.NewRequest(http.MethodGet, "/", nil), &auth.Claims{Sub: subject}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session, w.Result().Cookies()[0]
+}
+
+func Test_generatedSessionLastSeen(t *testing.T) {
+	tests := []struct {
+		name      string
+		after     time.Duration
+		wantSaves int
+	}{
+		{"recently seen", time.Minute, 0},
+		{"tenth of the idle timeout", 6 * time.Minute, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &countingStore{SessionStore: auth.NewMemoryStore()}
+			sessions, clock := newSessions(t, store)
+			_, cookie := login(t, sessions, "user")
+			store.saves = 0
+
+			clock.now = clock.now.Add(tt.after)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(cookie)
+			_, err := sessions.Load(r)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSaves, store.saves)
+		})
+	}
+}
+
+func Test_generatedSessionPurge(t *testing.T) {
+	for name, store := range sessionStores(t) {
+		t.Run(name, func(t *testing.T) {
+			sessions, clock := newSessions(t, store)
+			abandoned, _ := login(t, sessions, "a")
+
+			clock.now = clock.now.Add(2 * time.Hour)
+			active, _ := login(t, sessions, "b")
+
+			_, err := store.Get(context.Background(), abandoned.ID)
+			assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+			_, err = store.Get(context.Background(), active.ID)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_generatedSessionPurgeBrokenFile(t *testing.T) {
+	dir := t.TempDir()
+	store, err := auth.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions, clock := newSessions(t, store)
+	abandoned, _ := login(t, sessions, "a")
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	login(t, sessions, "b")
+
+	_, err = store.Get(context.Background(), abandoned.ID)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+	list, err := store.List(context.Background(), "b")
+	if assert.NoError(t, err) {
+		assert.Len(t, list, 1)
+	}
+}
+
+// unpurgeableStore fails to delete expired sessions.
+type unpurgeableStore struct{ auth.SessionStore }
+
+func (unpurgeableStore) DeleteExpired(context.Context, func(*auth.Session) bool) error {
+	return errors.New("disk /var/sessions is read-only")
+}
+
+func Test_generatedSessionPurgeError(t *testing.T) {
+	sessions, _ := newSessions(t, unpurgeableStore{auth.NewMemoryStore()})
+
+	// the login succeeds even though the purge fails
+	session, _ := login(t, sessions, "user")
+	_, err := sessions.Store.Get(context.Background(), session.ID)
+	assert.NoError(t, err)
+}
+
+// failingStore fails to read sessions.
+type failingStore struct{ auth.SessionStore }
+
+func (failingStore) Get(context.Context, string) (*auth.Session, error) {
+	return nil, errors.New("disk /var/sessions is full")
+}
+
+func Test_generatedSessionErrorDetail(t *testing.T) {
+	tests := []struct {
+		name       string
+		debug      bool
+		wantDetail bool
+	}{
+		{"hidden", false, false},
+		{"debug", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := auth.NewMemoryStore()
+			sessions, _ := newSessions(t, store)
+			_, cookie := login(t, sessions, "user")
+			sessions.Store = failingStore{store}
+
+			api.Debug = tt.debug
+			defer func() { api.Debug = false }()
+
+			authenticate := auth.Required(sessions, "https://idp.example.com", oauth2.Config{}, nil)
+			server := api.NewServer(securityService{}, authenticate, auth.Authorize(auth.DefaultRoleClaims...), nil)
+
+			r := httptest.NewRequest(http.MethodGet, "/tickets", nil)
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.Equal(t, tt.wantDetail, strings.Contains(w.Body.String(), "/var/sessions"), w.Body.String())
+		})
+	}
+}
+
+// undeletableStore fails to revoke sessions.
+type undeletableStore struct{ auth.SessionStore }
+
+func (undeletableStore) Delete(context.Context, string) error {
+	return errors.New("disk /var/sessions is read-only")
+}
+
+func Test_generatedLogoutErrorDetail(t *testing.T) {
+	tests := []struct {
+		name       string
+		debug      bool
+		wantDetail bool
+	}{
+		{"hidden", false, false},
+		{"debug", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := auth.NewMemoryStore()
+			sessions, _ := newSessions(t, store)
+			_, cookie := login(t, sessions, "user")
+			sessions.Store = undeletableStore{store}
+
+			api.Debug = tt.debug
+			defer func() { api.Debug = false }()
+
+			r := httptest.NewRequest(http.MethodPost, "/logout", nil)
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			auth.Logout(sessions, oauth2.Config{}, "", "")(w, r)
+
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+			assert.Equal(t, tt.wantDetail, strings.Contains(w.Body.String(), "/var/sessions"), w.Body.String())
+		})
+	}
+}
+
+// tokenEndpoint is an identity provider token endpoint that rotates the
+// refresh token and rejects used ones with invalid_grant.
+type tokenEndpoint struct {
+	mu           sync.Mutex
+	refreshToken string
+	status       int // answers with the status instead if set
+	calls        int
+}
+
+func (e *tokenEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls++
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case e.status != 0:
+		w.WriteHeader(e.status)
+		w.Write([]byte(`{"error":"temporarily_unavailable"}`))
+	case r.FormValue("refresh_token") != e.refreshToken:
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+	default:
+		e.refreshToken = fmt.Sprintf("refresh-%d", e.calls)
+		fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":%q,"token_type":"Bearer","expires_in":3600}`, e.calls, e.refreshToken)
+	}
+}
+
+// refreshServer returns a server whose sessions use the token endpoint and a
+// session whose access token expires soon.
+func refreshServer(t *testing.T, endpoint *tokenEndpoint, loginExpiry time.Duration) (http.Handler, *auth.Sessions, *http.Cookie) {
+	idp := httptest.NewServer(endpoint)
+	t.Cleanup(idp.Close)
+
+	sessions, clock := newSessions(t, auth.NewMemoryStore())
+	claims := &auth.Claims{Sub: "user", Exp: int(clock.now.Add(loginExpiry).Unix())}
+	claims.RealmAccess.Roles = []string{"read"}
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: endpoint.refreshToken, Expiry: clock.now.Add(30 * time.Second)}
+
+	w := httptest.NewRecorder()
+	if _, err := sessions.Create(w, httptest.NewRequest(http.MethodGet, "/", nil), claims, token); err != nil {
+		t.Fatal(err)
+	}
+
+	oauth2Config := oauth2.Config{ClientID: "app", Endpoint: oauth2.Endpoint{AuthURL: idp.URL + "/auth", TokenURL: idp.URL + "/token"}}
+	authenticate := auth.Required(sessions, idp.URL, oauth2Config, nil)
+	server := api.NewServer(securityService{}, authenticate, auth.Authorize(auth.DefaultRoleClaims...), nil)
+	return server, sessions, w.Result().Cookies()[0]
+}
+
+func Test_generatedRefresh(t *testing.T) {
+	tests := []struct {
+		name             string
+		refreshToken     string // accepted by the token endpoint
+		status           int
+		loginExpiry      time.Duration
+		wantStatus       int
+		wantRefreshToken string // of the stored session, empty if ended
+	}{
+		{"rotated", "refresh", 0, time.Hour, http.StatusOK, "refresh-1"},
+		{"unavailable", "refresh", http.StatusServiceUnavailable, time.Hour, http.StatusOK, "refresh"},
+		{"invalid grant", "other", 0, time.Hour, http.StatusFound, ""},
+		{"unavailable after login expired", "refresh", http.StatusServiceUnavailable, -time.Minute, http.StatusFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &tokenEndpoint{refreshToken: "refresh"}
+			server, sessions, cookie := refreshServer(t, endpoint, tt.loginExpiry)
+			endpoint.refreshToken, endpoint.status = tt.refreshToken, tt.status
+
+			r := httptest.NewRequest(http.MethodGet, "/tickets", nil)
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, r)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+
+			stored, err := sessions.List(context.Background(), "user")
+			assert.NoError(t, err)
+			if tt.wantRefreshToken == "" {
+				assert.Empty(t, stored)
+				return
+			}
+			if assert.Len(t, stored, 1) {
+				assert.Equal(t, tt.wantRefreshToken, stored[0].Token.RefreshToken)
+			}
+		})
+	}
+}
+
+func Test_generatedConcurrentRefresh(t *testing.T) {
+	endpoint := &tokenEndpoint{refreshToken: "refresh"}
+	server, _, cookie := refreshServer(t, endpoint, time.Hour)
+
+	var wg sync.WaitGroup
+	statuses := make([]int, 10)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodGet, "/tickets", nil)
+			r.AddCookie(cookie)
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, r)
+			statuses[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	for _, status := range statuses {
+		assert.Equal(t, http.StatusOK, status)
+	}
+	assert.Equal(t, 1, endpoint.calls)
+}
+
+func Test_generatedCookies(t *testing.T) {
+	oldKey, newKey := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+	tamper := func(cookie *http.Cookie) {
+		b := []byte(cookie.Value)
+		b[len(b)/2] ^= 'a' ^ 'b'
+		cookie.Value = string(b)
+	}
+
+	tests := []struct {
+		name    string
+		setName string
+		setKeys [][]byte
+		getKeys [][]byte
+		modify  func(*http.Cookie)
+		after   time.Duration
+		wantErr error
+	}{
+		{"valid", "state", [][]byte{oldKey}, [][]byte{oldKey}, nil, 0, nil},
+		{"tampered", "state", [][]byte{oldKey}, [][]byte{oldKey}, tamper, 0, auth.ErrInvalidCookie},
+		{"renamed", "other", [][]byte{oldKey}, [][]byte{oldKey}, nil, 0, auth.ErrInvalidCookie},
+		{"rotated key", "state", [][]byte{oldKey}, [][]byte{newKey, oldKey}, nil, 0, nil},
+		{"encrypted with first key", "state", [][]byte{newKey, oldKey}, [][]byte{newKey}, nil, 0, nil},
+		{"removed key", "state", [][]byte{oldKey}, [][]byte{newKey}, nil, 0, auth.ErrInvalidCookie},
+		{"expired", "state", [][]byte{oldKey}, [][]byte{oldKey}, nil, 2 * time.Minute, auth.ErrExpiredCookie},
+		{"not expired", "state", [][]byte{oldKey}, [][]byte{oldKey}, nil, 30 * time.Second, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fixedClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+			setCookies, err := auth.NewCookies(tt.setKeys...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			setCookies.Clock = clock
+			getCookies, err := auth.NewCookies(tt.getKeys...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			getCookies.Clock = clock
+
+			w := httptest.NewRecorder()
+			if err := setCookies.Set(w, tt.setName, "value", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			cookie := w.Result().Cookies()[0]
+			if tt.modify != nil {
+				tt.modify(cookie)
+			}
+			clock.now = clock.now.Add(tt.after)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			// the value is always presented as the state cookie
+			r.AddCookie(&http.Cookie{Name: "state", Value: cookie.Value})
+			var value string
+			err = getCookies.Get(r, "state", &value)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, "value", value)
+			}
+		})
+	}
+}
+
+func Test_generatedSessionTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(session *auth.Session)
+		after   time.Duration
+		wantErr error
+	}{
+		{"active", nil, 59 * time.Minute, nil},
+		{"idle", nil, time.Hour, auth.ErrSessionExpired},
+		{"absolute", func(session *auth.Session) { session.Created = session.Created.Add(-24 * time.Hour) }, 0, auth.ErrSessionExpired},
+		{"before absolute", func(session *auth.Session) { session.Created = session.Created.Add(-23 * time.Hour) }, 0, nil},
+	}
+	for _, tt := range tests {
+		for storeName, store := range sessionStores(t) {
+			t.Run(tt.name+" "+storeName, func(t *testing.T) {
+				ctx := context.Background()
+				sessions, clock := newSessions(t, store)
+				session, cookie := login(t, sessions, "user")
+				if tt.modify != nil {
+					tt.modify(session)
+					if err := store.Save(ctx, session); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				clock.now = clock.now.Add(tt.after)
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.AddCookie(cookie)
+				_, err := sessions.Load(r)
+				if tt.wantErr == nil {
+					assert.NoError(t, err)
+					return
+				}
+				assert.ErrorIs(t, err, tt.wantErr)
+				_, err = store.Get(ctx, session.ID)
+				assert.ErrorIs(t, err, auth.ErrSessionNotFound, "the expired session was kept")
+			})
+		}
+	}
+}
+
+func Test_generatedFileStoreIDs(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := auth.NewFileStore(filepath.Join(dir, "sessions"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a session outside of the store that must not be reachable
+	outside, err := auth.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outside.Save(ctx, &auth.Session{ID: "outside", Subject: "user"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"parent directory", "../outside"},
+		{"absolute path", filepath.Join(dir, "outside")},
+		{"dot", "."},
+		{"empty", ""},
+		{"separator", "a/b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Get(ctx, tt.id)
+			assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+			assert.ErrorIs(t, store.Save(ctx, &auth.Session{ID: tt.id}), auth.ErrSessionNotFound)
+			assert.NoError(t, store.Delete(ctx, tt.id))
+		})
+	}
+
+	_, err = outside.Get(ctx, "outside")
+	assert.NoError(t, err, "a session outside of the store was removed")
+}
+
+func Test_generatedSessionRevoke(t *testing.T) {
+	tests := []struct {
+		name      string
+		revoke    func(sessions *auth.Sessions, first *auth.Session) error
+		remaining []int // indexes of the sessions of the user that remain
+	}{
+		{"none", func(*auth.Sessions, *auth.Session) error { return nil }, []int{0, 1}},
+		{"one", func(sessions *auth.Sessions, first *auth.Session) error {
+			return sessions.Revoke(context.Background(), first.ID)
+		}, []int{1}},
+		{"all", func(sessions *auth.Sessions, _ *auth.Session) error {
+			return sessions.RevokeAll(context.Background(), "user")
+		}, nil},
+	}
+	for _, tt := range tests {
+		for storeName, store := range sessionStores(t) {
+			t.Run(tt.name+" "+storeName, func(t *testing.T) {
+				ctx := context.Background()
+				sessions, clock := newSessions(t, store)
+				first, _ := login(t, sessions, "user")
+				clock.now = clock.now.Add(time.Minute)
+				second, _ := login(t, sessions, "user")
+				other, _ := login(t, sessions, "other")
+				created := []*auth.Session{first, second}
+
+				assert.NoError(t, tt.revoke(sessions, first))
+
+				list, err := sessions.List(ctx, "user")
+				if !assert.NoError(t, err) {
+					return
+				}
+				var ids, wantIDs []string
+				for _, session := range list {
+					ids = append(ids, session.ID)
+				}
+				for _, i := range tt.remaining {
+					wantIDs = append(wantIDs, created[i].ID)
+				}
+				assert.Equal(t, wantIDs, ids)
+
+				_, err = store.Get(ctx, other.ID)
+				assert.NoError(t, err, "the session of another user was revoked")
+			})
+		}
+	}
+}
+
+func Test_generatedLogout(t *testing.T) {
+	tests := []struct {
+		name          string
+		endSessionURL string
+		postLogoutURL string
+		loggedIn      bool
+		wantLocation  string
+	}{
+		{"default", "", "", true, "/"},
+		{"post logout url", "", "/goodbye", true, "/goodbye"},
+		{"end session endpoint", "https://idp.example.com/logout?realm=test", "https://app.example.com/", true, "https://idp.example.com/logout?client_id=app&id_token_hint=id-token&post_logout_redirect_uri=https%3A%2F%2Fapp.example.com%2F&realm=test"},
+		{"end session endpoint without post logout url", "https://idp.example.com/logout", "", true, "https://idp.example.com/logout?client_id=app&id_token_hint=id-token"},
+		{"logged out", "https://idp.example.com/logout", "", false, "https://idp.example.com/logout?client_id=app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := auth.NewMemoryStore()
+			sessions, _ := newSessions(t, store)
+			session, cookie := login(t, sessions, "user")
+			session.IDToken = "id-token"
+			if err := store.Save(ctx, session); err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/logout", nil)
+			if tt.loggedIn {
+				r.AddCookie(cookie)
+			}
+			w := httptest.NewRecorder()
+			auth.Logout(sessions, oauth2.Config{ClientID: "app"}, tt.endSessionURL, tt.postLogoutURL)(w, r)
+
+			assert.Equal(t, http.StatusFound, w.Code)
+			assert.Equal(t, tt.wantLocation, w.Header().Get("Location"))
+			if cookies := w.Result().Cookies(); assert.Len(t, cookies, 1) {
+				assert.Equal(t, -1, cookies[0].MaxAge, "the session cookie was kept")
+			}
+			_, err := store.Get(ctx, session.ID)
+			assert.Equal(t, tt.loggedIn, errors.Is(err, auth.ErrSessionNotFound), "only the session of the cookie is ended")
+		})
+	}
+}
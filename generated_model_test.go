@@ -0,0 +1,108 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cugu/swagger-go-chi/testdata/polymorphic/generated/api"
+	"github.com/cugu/swagger-go-chi/testdata/polymorphic/generated/model"
+)
+
+// The tests in this file run the generated packages of testdata/polymorphic.
+
+func Test_generatedUnionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape model.Shape
+		want  string
+	}{
+		{"circle", &model.Circle{Radius: 1}, `{"kind":"circle","radius":1}`},
+		{"wrong value", &model.Circle{Kind: "square", Radius: 1}, `{"kind":"circle","radius":1}`},
+		{"several values", &model.Square{Side: 2}, `{"kind":"box","side":2}`},
+		{"other value", &model.Square{Kind: "square", Side: 2}, `{"kind":"square","side":2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(&model.Drawing{Shapes: []*model.ShapeValue{{Shape: tt.shape}}})
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.JSONEq(t, `{"shapes":[`+tt.want+`]}`, string(b))
+
+			drawing := &model.Drawing{}
+			if assert.NoError(t, json.Unmarshal(b, drawing)) && assert.Len(t, drawing.Shapes, 1) {
+				assert.IsType(t, tt.shape, drawing.Shapes[0].Shape)
+			}
+		})
+	}
+}
+
+func Test_generatedInheritanceRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		pet  model.Pet
+		want string
+	}{
+		{"cat", &model.Cat{PetBase: model.PetBase{Name: "tom"}}, `{"petType":"Cat","name":"tom"}`},
+		{"dog", &model.Dog{PetBase: model.PetBase{Name: "rex"}}, `{"petType":"dog","name":"rex"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.pet)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.JSONEq(t, tt.want, string(b))
+
+			pet, err := model.UnmarshalPet(b)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.pet, withoutPetType(pet))
+			}
+		})
+	}
+}
+
+// withoutPetType clears the petType of a decoded pet, which is set on decoding
+// but not by the test cases.
+func withoutPetType(pet model.Pet) model.Pet {
+	switch pet := pet.(type) {
+	case *model.Cat:
+		pet.PetType = ""
+	case *model.Dog:
+		pet.PetType = ""
+	}
+	return pet
+}
+
+// petService returns the pets it gets.
+type petService struct{}
+
+func (petService) CreatePet(_ context.Context, pet model.Pet) (model.Pet, error) {
+	return pet, nil
+}
+
+func (petService) CreatePets(_ context.Context, pets []model.Pet) ([]model.Pet, error) {
+	return pets, nil
+}
+
+func (petService) CreateDrawing(_ context.Context, drawing *model.Drawing) (*model.Drawing, error) {
+	return drawing, nil
+}
+
+func Test_generatedUnionArray(t *testing.T) {
+	body := `[{"petType":"Cat","name":"tom"},null,{"petType":"dog","name":"rex","bark":true}]`
+
+	r := httptest.NewRequest(http.MethodPost, "/pets/batch", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	api.NewServer(petService{}, nil, nil, nil).ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, body, w.Body.String())
+}
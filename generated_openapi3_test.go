@@ -0,0 +1,64 @@
+package main_test
+
+import (
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cugu/swagger-go-chi/testdata/openapi3/generated/api"
+	"github.com/cugu/swagger-go-chi/testdata/openapi3/generated/auth"
+	"github.com/cugu/swagger-go-chi/testdata/openapi3/generated/model"
+)
+
+// The tests in this file run the generated packages of testdata/openapi3.
+
+type userService struct{}
+
+func (userService) UploadFile(context.Context, []string, []*multipart.FileHeader) error { return nil }
+
+func (userService) ListUsers(context.Context, *int) ([]*model.User, error) {
+	return []*model.User{}, nil
+}
+
+func (userService) CreateUser(_ context.Context, user *model.User) (*model.User, error) {
+	return user, nil
+}
+
+func (userService) GetUser(_ context.Context, id string) (*model.User, error) {
+	return &model.User{Name: id}, nil
+}
+
+func (userService) DeleteUser(context.Context, string) error { return nil }
+
+func Test_generatedBearerScheme(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *auth.Claims
+		wantStatus int
+	}{
+		{"user", &auth.Claims{Sub: "bob"}, http.StatusNoContent},
+		{"no user", nil, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticate := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if tt.user != nil {
+						r = r.WithContext(context.WithValue(r.Context(), auth.UserContext, tt.user))
+					}
+					next.ServeHTTP(w, r)
+				})
+			}
+			server := api.NewServer(userService{}, authenticate, auth.Authorize(auth.DefaultRoleClaims...), nil)
+
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users/bob", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
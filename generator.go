@@ -3,15 +3,16 @@ package main
 import (
 	"bytes"
 	"embed"
+	"errors"
 	"fmt"
-	"go/format"
 	"io/fs"
-	"log"
 	"os"
+	"strings"
 	"testing/fstest"
 	"text/template"
 
 	"github.com/rogpeppe/go-internal/modfile"
+	"golang.org/x/tools/imports"
 	"gopkg.in/yaml.v3"
 )
 
@@ -24,7 +25,10 @@ var time embed.FS
 //go:embed pointer/*
 var pointer embed.FS
 
-var packages = []fs.FS{api, time, pointer}
+//go:embed model/*
+var model embed.FS
+
+var packages = []fs.FS{api, time, pointer, model}
 
 //go:embed templates/*
 var templateFS embed.FS
@@ -34,6 +38,8 @@ var generations = []*generation{
 	{"api", "test_api.go", template.Must(template.New("test_api.gotmpl").Funcs(funcs).ParseFS(templateFS, "templates/test_api.gotmpl"))},
 	{"model", "model.go", template.Must(template.New("model.gotmpl").Funcs(funcs).ParseFS(templateFS, "templates/model.gotmpl"))},
 	{"auth", "auth.go", template.Must(template.New("auth.gotmpl").Funcs(funcs).ParseFS(templateFS, "templates/auth.gotmpl"))},
+	{"auth", "cookie.go", template.Must(template.New("cookie.gotmpl").Funcs(funcs).ParseFS(templateFS, "templates/cookie.gotmpl"))},
+	{"auth", "session.go", template.Must(template.New("session.gotmpl").Funcs(funcs).ParseFS(templateFS, "templates/session.gotmpl"))},
 	{"cli", "cli.go", template.Must(template.New("cli.gotmpl").Funcs(funcs).ParseFS(templateFS, "templates/cli.gotmpl"))},
 }
 
@@ -48,11 +54,32 @@ type TemplateData struct {
 	Swagger    *Swagger
 }
 
-func generate(importPath string, yamlData []byte) (fstest.MapFS, error) {
-	swagger := &Swagger{}
-	if err := yaml.Unmarshal(yamlData, swagger); err != nil {
+func generate(importPath string, filename string) (fstest.MapFS, error) {
+	doc, loc, err := load(filename)
+	if doc == nil {
 		return nil, err
 	}
+	loadErrs, err := loc.specErrors(err)
+	if err != nil {
+		return nil, err
+	}
+
+	// collect the unresolved references, the type errors, the conversion
+	// problems and the problems found by check into a single report
+	swagger, convertErrs, err := parse(doc)
+	errs, err := loc.specErrors(err)
+	if err != nil {
+		return nil, err
+	}
+	errs = append(append(loadErrs, errs...), withoutUnresolved(convertErrs, loadErrs)...)
+	if swagger != nil {
+		errs = append(errs, withoutUnresolved(check(swagger), loadErrs)...)
+	}
+	if err := loc.locate(errs); err != nil {
+		return nil, err
+	}
+
+	normalize(swagger)
 
 	data := &TemplateData{
 		ImportPath: importPath,
@@ -67,17 +94,17 @@ func generate(importPath string, yamlData []byte) (fstest.MapFS, error) {
 			return nil, err
 		}
 
-		fmtCode, err := format.Source(buf.Bytes())
+		fmtCode, err := formatSource(buf.Bytes())
 		if err != nil {
-			log.Println(err)
-			fmtCode = buf.Bytes()
+			return nil, fmt.Errorf("%s/%s: generated code is invalid: %w", templ.Package, templ.Name, err)
 		}
 		files[templ.Package+"/"+templ.Name] = &fstest.MapFile{Data: fmtCode, Mode: os.ModePerm}
 	}
 
 	for _, fsys := range packages {
 		err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
-			if d.IsDir() {
+			// the tests of the static packages are not part of the generated code
+			if d.IsDir() || strings.HasSuffix(path, "_test.go") {
 				return nil
 			}
 
@@ -97,6 +124,43 @@ func generate(importPath string, yamlData []byte) (fstest.MapFS, error) {
 	return files, nil
 }
 
+// formatSource removes unused imports from the generated code and formats it.
+func formatSource(src []byte) ([]byte, error) {
+	return imports.Process("", src, &imports.Options{Comments: true, TabIndent: true, TabWidth: 8})
+}
+
+// parse reads a Swagger 2.0 or OpenAPI 3.0 document. OpenAPI 3.0 documents
+// are converted to Swagger 2.0, the problems of the conversion are returned
+// as SpecErrors. Type errors are returned together with the partially
+// decoded document.
+func parse(doc *yaml.Node) (*Swagger, SpecErrors, error) {
+	if version := mappingValue(doc, "openapi"); version != nil {
+		if !strings.HasPrefix(version.Value, "3.0") {
+			return nil, nil, SpecErrors{errorf(version, "unsupported OpenAPI version %s", version.Value)}
+		}
+
+		openAPI := &OpenAPI{}
+		decodeErr := doc.Decode(openAPI)
+		var typeError *yaml.TypeError
+		if decodeErr != nil && !errors.As(decodeErr, &typeError) {
+			return nil, nil, decodeErr
+		}
+
+		swagger, errs := convertOpenAPI(openAPI)
+		swagger.node = doc
+		return swagger, errs, decodeErr
+	}
+
+	swagger := &Swagger{}
+	err := doc.Decode(swagger)
+	var typeError *yaml.TypeError
+	if err != nil && !errors.As(err, &typeError) {
+		return nil, nil, err
+	}
+	swagger.node = doc
+	return swagger, nil, err
+}
+
 func modulePath() (string, error) {
 	b, err := os.ReadFile("go.mod")
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path"
 	"testing"
 	"testing/fstest"
@@ -19,12 +20,7 @@ func Test_generate(t *testing.T) {
 
 	for _, dir := range entries {
 		t.Run(dir.Name(), func(t *testing.T) {
-			yamlData, err := os.ReadFile(path.Join("testdata", dir.Name(), "swagger.yml"))
-			if err != nil {
-				t.Fatal(err)
-			}
-
-			got, err := generate("github.com/cugu/swagger-go-chi/testdata/"+dir.Name()+"/generated", yamlData)
+			got, err := generate("github.com/cugu/swagger-go-chi/testdata/"+dir.Name()+"/generated", path.Join("testdata", dir.Name(), "swagger.yml"))
 			if (err != nil) != false {
 				t.Errorf("generate() error = %v, wantErr %v", err, false)
 				return
@@ -37,6 +33,12 @@ func Test_generate(t *testing.T) {
 
 			assert.Equal(t, want, got)
 			assertFS(t, want, got)
+
+			// the generated packages have to compile
+			cmd := exec.Command("go", "vet", "./"+path.Join("testdata", dir.Name(), "generated")+"/...")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("go vet: %v\n%s", err, out)
+			}
 		})
 	}
 }
@@ -86,3 +88,24 @@ func toMapFS(dirPath string) (fstest.MapFS, error) {
 	})
 	return want, err
 }
+
+func Test_formatSource(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unused", "package a\nimport (\n\"fmt\"\n\"time\"\n)\nvar _ = time.Now", "package a\n\nimport (\n\t\"time\"\n)\n\nvar _ = time.Now\n"},
+		{"all unused", "package a\nimport \"fmt\"\nvar _ = 1", "package a\n\nvar _ = 1\n"},
+		{"import names", "package a\nimport (\n\"github.com/coreos/go-oidc\"\n\"gopkg.in/yaml.v3\"\nx \"io\"\n_ \"embed\"\n)\nvar _, _, _ = oidc.X, yaml.Y, x.Z", "package a\n\nimport (\n\t_ \"embed\"\n\tx \"io\"\n\n\t\"github.com/coreos/go-oidc\"\n\t\"gopkg.in/yaml.v3\"\n)\n\nvar _, _, _ = oidc.X, yaml.Y, x.Z\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatSource([]byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
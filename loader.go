@@ -0,0 +1,465 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"gopkg.in/yaml.v3"
+)
+
+// loader bundles a document and all documents it references via relative
+// $ref into a single in-memory document.
+//
+// Schemas referenced from other files or by nested JSON pointers are added
+// to the definitions of the root document and their references are
+// rewritten to point there. All other references (path items, parameters,
+// responses, ...) are replaced by the referenced content.
+type loader struct {
+	root        string
+	doc         *yaml.Node
+	docs        map[string]*yaml.Node
+	definitions []string // path of the definitions in the root document
+	names       map[string]string
+	taken       map[string]bool
+	inlined     map[string]bool
+	inlining    map[string]bool
+	files       map[*yaml.Node]string // source file of every node
+	errors      SpecErrors
+}
+
+// load reads the document at filename and resolves all references into it.
+// The returned locator finds the source of the nodes of the document for
+// error messages. References that cannot be resolved are returned as
+// SpecErrors together with the document, so they are reported along with
+// the other problems of the document.
+func load(filename string) (*yaml.Node, *locator, error) {
+	root, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l := &loader{
+		root:        root,
+		docs:        map[string]*yaml.Node{},
+		definitions: []string{"definitions"},
+		names:       map[string]string{},
+		taken:       map[string]bool{},
+		inlined:     map[string]bool{},
+		inlining:    map[string]bool{},
+		files:       map[*yaml.Node]string{},
+	}
+
+	doc, err := l.document(root)
+	if err != nil {
+		var specError *SpecError
+		if errors.As(err, &specError) {
+			return nil, nil, SpecErrors{specError}
+		}
+		return nil, nil, err
+	}
+	l.doc = doc
+
+	if mappingValue(doc, "openapi") != nil {
+		l.definitions = []string{"components", "schemas"}
+	}
+
+	definitions := lookupPath(doc, l.definitions)
+	if definitions != nil {
+		for i := 0; i < len(definitions.Content); i += 2 {
+			l.taken[definitions.Content[i].Value] = true
+		}
+		for i := 0; i < len(definitions.Content); i += 2 {
+			l.walkDefinition(definitions.Content[i].Value, definitions.Content[i+1])
+		}
+	}
+
+	l.walk(root, doc, false)
+
+	loc := &locator{root: root, doc: doc, docs: l.docs, files: l.files}
+	return doc, loc, loc.locate(l.errors)
+}
+
+// document reads and caches the root node of a file.
+func (l *loader) document(filename string) (*yaml.Node, error) {
+	if doc, ok := l.docs[filename]; ok {
+		return doc, nil
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(b, doc); err != nil {
+		if match := lineError.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			return nil, &SpecError{File: filename, Line: line, Message: match[2]}
+		}
+		return nil, &SpecError{File: filename, Message: err.Error()}
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	} else {
+		doc = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	walkNodes(doc, func(node *yaml.Node) bool {
+		l.files[node] = filename
+		return true
+	})
+
+	l.docs[filename] = doc
+	return doc, nil
+}
+
+// walkDefinition resolves a definition of the root document. A definition
+// that only references a schema in another file takes over the content and
+// the name of the referenced schema.
+func (l *loader) walkDefinition(name string, node *yaml.Node) {
+	ref := mappingValue(node, "$ref")
+	if ref == nil || strings.HasPrefix(ref.Value, "#") {
+		return
+	}
+
+	file, pointer, target, err := l.resolve(l.root, ref.Value)
+	if err != nil {
+		l.errors = append(l.errors, errorf(ref, "%s", err))
+		return
+	}
+	key := file + "#" + pointer
+	if _, ok := l.names[key]; ok {
+		return
+	}
+	l.names[key] = name
+
+	l.walk(file, target, true)
+	*node = *target
+	l.files[node] = file
+}
+
+// walk resolves all references in node, which is part of file. The schema
+// flag tells whether node is a JSON schema. References that cannot be
+// resolved are collected as errors.
+func (l *loader) walk(file string, node *yaml.Node, schema bool) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			l.walk(file, child, schema)
+		}
+	case yaml.MappingNode:
+		if ref := mappingValue(node, "$ref"); ref != nil {
+			var err error
+			if schema {
+				err = l.schemaRef(file, ref)
+			} else {
+				err = l.inline(file, node, ref.Value)
+			}
+			if err != nil {
+				l.errors = append(l.errors, errorf(ref, "%s", err))
+			}
+			return
+		}
+
+		for i := 0; i < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+
+			switch {
+			case key == "example" || key == "examples" || key == "x-example" || key == "default" || key == "enum":
+			case key == "properties" || key == "patternProperties" || key == "definitions" || (!schema && key == "schemas"):
+				l.walkSchemas(file, value)
+			case key == "schema" || key == "items" || key == "additionalProperties" || key == "not" ||
+				key == "allOf" || key == "anyOf" || key == "oneOf":
+				l.walk(file, value, true)
+			case schema && key == "discriminator":
+				l.discriminatorMapping(file, mappingValue(value, "mapping"))
+			case !schema:
+				l.walk(file, value, false)
+			}
+		}
+	}
+}
+
+func (l *loader) walkSchemas(file string, node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 1; i < len(node.Content); i += 2 {
+		l.walk(file, node.Content[i], true)
+	}
+}
+
+// schemaRef rewrites a schema reference to point to a definition of the
+// root document, adding the referenced schema to the definitions if needed.
+func (l *loader) schemaRef(file string, ref *yaml.Node) error {
+	targetFile, pointer, target, err := l.resolve(file, ref.Value)
+	if err != nil {
+		return err
+	}
+
+	if targetFile == l.root {
+		if name, ok := l.definitionName(pointer); ok {
+			ref.Value = l.localRef(name)
+			return nil
+		}
+	}
+
+	key := targetFile + "#" + pointer
+	if name, ok := l.names[key]; ok {
+		ref.Value = l.localRef(name)
+		return nil
+	}
+
+	name := l.name(targetFile, pointer)
+	l.names[key] = name
+	definitions := ensurePath(l.doc, l.definitions)
+	definitions.Content = append(definitions.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, target)
+	ref.Value = l.localRef(name)
+
+	l.walk(targetFile, target, true)
+	return nil
+}
+
+// discriminatorMapping rewrites the references of an OpenAPI 3.0
+// discriminator mapping like schema references. Values without # or / are
+// schema names and are kept.
+func (l *loader) discriminatorMapping(file string, mapping *yaml.Node) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 1; i < len(mapping.Content); i += 2 {
+		ref := mapping.Content[i]
+		if !strings.ContainsAny(ref.Value, "#/") {
+			continue
+		}
+		if err := l.schemaRef(file, ref); err != nil {
+			l.errors = append(l.errors, errorf(ref, "%s", err))
+		}
+	}
+}
+
+// inline replaces node with the content it references.
+func (l *loader) inline(file string, node *yaml.Node, ref string) error {
+	targetFile, pointer, target, err := l.resolve(file, ref)
+	if err != nil {
+		return err
+	}
+
+	key := targetFile + "#" + pointer
+	if l.inlining[key] {
+		return fmt.Errorf("cyclic reference %s", ref)
+	}
+
+	if !l.inlined[key] {
+		l.inlining[key] = true
+		l.walk(targetFile, target, false)
+		delete(l.inlining, key)
+		l.inlined[key] = true
+	}
+
+	*node = *target
+	l.files[node] = targetFile
+	return nil
+}
+
+// resolve returns the file, the JSON pointer and the node a reference
+// points to. References to references are followed.
+func (l *loader) resolve(file, ref string) (string, string, *yaml.Node, error) {
+	seen := map[string]bool{}
+	for {
+		targetFile, pointer, err := splitRef(file, ref)
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		key := targetFile + "#" + pointer
+		if seen[key] {
+			return "", "", nil, fmt.Errorf("cyclic reference %s", ref)
+		}
+		seen[key] = true
+
+		doc, err := l.document(targetFile)
+		if err != nil {
+			var pathError *fs.PathError
+			if errors.As(err, &pathError) {
+				err = pathError.Err
+			}
+			return "", "", nil, fmt.Errorf("invalid reference %s: %w", ref, err)
+		}
+
+		target, err := lookupPointer(doc, pointer)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("invalid reference %s: %w", ref, err)
+		}
+
+		next := mappingValue(target, "$ref")
+		if next == nil || len(target.Content) != 2 {
+			return targetFile, pointer, target, nil
+		}
+		file, ref = targetFile, next.Value
+	}
+}
+
+// definitionName returns the definition name if pointer points directly to a
+// definition of the root document.
+func (l *loader) definitionName(pointer string) (string, bool) {
+	prefix := "/" + strings.Join(l.definitions, "/") + "/"
+	if !strings.HasPrefix(pointer, prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(pointer, prefix)
+	if strings.Contains(name, "/") {
+		return "", false
+	}
+	return unescapePointer(name), true
+}
+
+func (l *loader) localRef(name string) string {
+	return "#/" + strings.Join(l.definitions, "/") + "/" + name
+}
+
+// name derives an unused definition name from the file name and the JSON
+// pointer of a schema, preferring short names.
+func (l *loader) name(file, pointer string) string {
+	stem := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+
+	var parts []string
+	for _, segment := range strings.Split(pointer, "/") {
+		segment = unescapePointer(segment)
+		switch segment {
+		case "", "definitions", "components", "schemas", "properties":
+		case "items":
+			if len(parts) > 0 {
+				parts[len(parts)-1] += "Item"
+			}
+		case "additionalProperties":
+			if len(parts) > 0 {
+				parts[len(parts)-1] += "Value"
+			}
+		default:
+			parts = append(parts, strcase.ToCamel(segment))
+		}
+	}
+
+	var candidates []string
+	for i := len(parts) - 1; i >= 0; i-- {
+		candidates = append(candidates, strings.Join(parts[i:], ""))
+	}
+	candidates = append(candidates, strcase.ToCamel(stem)+strings.Join(parts, ""))
+
+	for _, candidate := range candidates {
+		if !l.taken[candidate] {
+			l.taken[candidate] = true
+			return candidate
+		}
+	}
+
+	base := candidates[len(candidates)-1]
+	for i := 2; ; i++ {
+		candidate := base + strconv.Itoa(i)
+		if !l.taken[candidate] {
+			l.taken[candidate] = true
+			return candidate
+		}
+	}
+}
+
+func lookupPath(node *yaml.Node, keys []string) *yaml.Node {
+	for _, key := range keys {
+		node = mappingValue(node, key)
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+func ensurePath(node *yaml.Node, keys []string) *yaml.Node {
+	for _, key := range keys {
+		value := mappingValue(node, key)
+		if value == nil {
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		}
+		node = value
+	}
+	return node
+}
+
+// splitRef splits a reference into the absolute file name and the JSON
+// pointer. References without file part point into file.
+func splitRef(file, ref string) (string, string, error) {
+	filePart, pointer, _ := strings.Cut(ref, "#")
+
+	if strings.Contains(filePart, "://") {
+		return "", "", fmt.Errorf("remote reference %s is not supported", ref)
+	}
+
+	if filePart != "" {
+		filePart, err := url.PathUnescape(filePart)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid reference %s: %w", ref, err)
+		}
+		file = filepath.Join(filepath.Dir(file), filepath.FromSlash(filePart))
+	}
+
+	return file, pointer, nil
+}
+
+// lookupPointer returns the node a JSON pointer points to.
+func lookupPointer(node *yaml.Node, pointer string) (*yaml.Node, error) {
+	if pointer == "" || pointer == "/" {
+		return node, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("pointer %s must start with /", pointer)
+	}
+
+	for _, segment := range strings.Split(pointer[1:], "/") {
+		segment = unescapePointer(segment)
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			value := mappingValue(node, segment)
+			if value == nil {
+				return nil, fmt.Errorf("%s not found", segment)
+			}
+			node = value
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node.Content) {
+				return nil, fmt.Errorf("index %s not found", segment)
+			}
+			node = node.Content[i]
+		default:
+			return nil, fmt.Errorf("%s not found", segment)
+		}
+	}
+	return node, nil
+}
+
+func unescapePointer(segment string) string {
+	if s, err := url.PathUnescape(segment); err == nil {
+		segment = s
+	}
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
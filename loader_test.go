@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_load(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "external schema",
+			files: map[string]string{
+				"swagger.yml":       "definitions:\n  Group: { properties: { owner: { $ref: './schemas/user.yaml#/User' } } }\n",
+				"schemas/user.yaml": "User: { type: object }\n",
+			},
+			want: "definitions:\n    Group: {properties: {owner: {$ref: '#/definitions/User'}}}\n    User: {type: object}\n",
+		},
+		{
+			name: "name collision",
+			files: map[string]string{
+				"swagger.yml": "definitions:\n  User: { properties: { other: { $ref: './other.yaml#/User' } } }\n",
+				"other.yaml":  "User: { type: object }\n",
+			},
+			want: "definitions:\n    User: {properties: {other: {$ref: '#/definitions/OtherUser'}}}\n    OtherUser: {type: object}\n",
+		},
+		{
+			name: "nested pointer",
+			files: map[string]string{
+				"swagger.yml": "definitions:\n  User: { properties: { address: { type: object } } }\n  Company: { properties: { address: { $ref: '#/definitions/User/properties/address' } } }\n",
+			},
+			want: "definitions:\n    User: {properties: {address: {type: object}}}\n    Company: {properties: {address: {$ref: '#/definitions/Address'}}}\n    Address: {type: object}\n",
+		},
+		{
+			name: "inline path",
+			files: map[string]string{
+				"swagger.yml":      "paths:\n  /users: { $ref: './paths/users.yaml' }\n",
+				"paths/users.yaml": "get: { operationId: listUsers }\n",
+			},
+			want: "paths:\n    /users:\n        get: {operationId: listUsers}\n",
+		},
+		{
+			name: "recursive schema",
+			files: map[string]string{
+				"swagger.yml": "definitions:\n  User: { $ref: './user.yaml' }\n",
+				"user.yaml":   "properties: { manager: { $ref: '#' } }\n",
+			},
+			want: "definitions:\n    User:\n        properties: {manager: {$ref: '#/definitions/User'}}\n",
+		},
+		{
+			name: "discriminator mapping",
+			files: map[string]string{
+				"swagger.yml": "openapi: 3.0.3\ncomponents:\n  schemas:\n    Pet: { $ref: './pets.yaml#/Pet' }\n",
+				"pets.yaml":   "Pet:\n  oneOf: [ { $ref: '#/Cat' } ]\n  discriminator: { propertyName: kind, mapping: { cat: '#/Cat', dog: Dog } }\nCat: { type: object }\n",
+			},
+			want: "openapi: 3.0.3\ncomponents:\n    schemas:\n        Pet:\n            oneOf: [{$ref: '#/components/schemas/Cat'}]\n            discriminator: {propertyName: kind, mapping: {cat: '#/components/schemas/Cat', dog: Dog}}\n        Cat: {type: object}\n",
+		},
+		{
+			name: "cyclic path",
+			files: map[string]string{
+				"swagger.yml": "paths:\n  /users: { $ref: './a.yaml' }\n",
+				"a.yaml":      "get: { $ref: './b.yaml' }\n",
+				"b.yaml":      "x: { $ref: './a.yaml' }\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "cyclic alias",
+			files: map[string]string{
+				"swagger.yml": "definitions:\n  User: { properties: { a: { $ref: './a.yaml' } } }\n",
+				"a.yaml":      "$ref: './b.yaml'\n",
+				"b.yaml":      "$ref: './a.yaml'\n",
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"swagger.yml": "paths:\n  /users: { $ref: './missing.yaml' }\n",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), os.ModePerm); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, _, err := load(filepath.Join(dir, "swagger.yml"))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			b, err := yaml.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, string(b))
+		})
+	}
+}
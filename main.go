@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"path"
@@ -16,6 +18,12 @@ type Config struct {
 
 func main() {
 	if err := run(); err != nil {
+		// problems of the API description are reported without log prefix
+		var specErrors SpecErrors
+		if errors.As(err, &specErrors) {
+			fmt.Fprintln(os.Stderr, specErrors)
+			os.Exit(1)
+		}
 		log.Fatalln(err)
 	}
 }
@@ -26,17 +34,12 @@ func run() error {
 	config := &Config{}
 	kong.Parse(config)
 
-	yamlData, err := os.ReadFile(config.SwaggerYAML)
-	if err != nil {
-		return err
-	}
-
 	modulePath, err := modulePath()
 	if err != nil {
 		return err
 	}
 
-	files, err := generate(modulePath+"/"+config.Directory, yamlData)
+	files, err := generate(modulePath+"/"+config.Directory, config.SwaggerYAML)
 	if err != nil {
 		return err
 	}
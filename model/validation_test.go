@@ -0,0 +1,33 @@
+package model
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator_IP(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    net.IP
+		wantIPv4 bool
+		wantIPv6 bool
+	}{
+		{"ipv4", net.ParseIP("192.0.2.1"), true, false},
+		{"ipv6", net.ParseIP("2001:db8::1"), false, true},
+		{"ipv4 mapped", net.ParseIP("::ffff:192.0.2.1"), true, false},
+		{"missing", nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Validator{}
+			v.IPv4("ip", tt.value)
+			assert.Equal(t, tt.wantIPv4, v.Err() == nil, "IPv4")
+
+			v = &Validator{}
+			v.IPv6("ip", tt.value)
+			assert.Equal(t, tt.wantIPv6, v.Err() == nil, "IPv6")
+		})
+	}
+}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// normalize prepares a parsed document for the templates. Inline object
+// schemas are moved to the definitions, so every object gets a named type,
+// references to definitions without a named type are inlined, polymorphic
+// schemas are unified to oneOf with a discriminator mapping and
+// references are linked to their definitions.
+func normalize(swagger *Swagger) {
+	if swagger.Definitions == nil {
+		swagger.Definitions = map[string]*Schema{}
+	}
+
+	hoistSchemas(swagger)
+	inlineAliases(swagger)
+	unions(swagger)
+	link(swagger)
+	mediaTypes(swagger)
+	security(swagger)
+	warnFormats(swagger)
+}
+
+// defaultMediaTypes are consumed and produced by operations without
+// consumes or produces.
+var defaultMediaTypes = []string{"application/json"}
+
+// formMediaTypes are consumed by operations with form parameters that do
+// not declare a form media type.
+var formMediaTypes = []string{"multipart/form-data", "application/x-www-form-urlencoded"}
+
+// mediaTypes sets the media types operations consume and produce to the ones
+// of the document if they declare none.
+func mediaTypes(swagger *Swagger) {
+	for _, lpath := range sortedKeys(swagger.Paths) {
+		for _, operation := range swagger.Paths[lpath].operations() {
+			if len(operation.Consumes) == 0 {
+				operation.Consumes = swagger.Consumes
+			}
+			if len(operation.Consumes) == 0 {
+				operation.Consumes = defaultMediaTypes
+			}
+			if len(operation.Produces) == 0 {
+				operation.Produces = swagger.Produces
+			}
+			if len(operation.Produces) == 0 {
+				operation.Produces = defaultMediaTypes
+			}
+
+			for _, parameter := range operation.Parameters {
+				if parameter.In == "formData" && !containsAny(operation.Consumes, formMediaTypes) {
+					operation.Consumes = formMediaTypes
+				}
+			}
+		}
+	}
+}
+
+// security sets the security requirements of operations without security
+// to the ones of the document. Operations with an empty list are public.
+func security(swagger *Swagger) {
+	for _, lpath := range sortedKeys(swagger.Paths) {
+		for _, operation := range swagger.Paths[lpath].operations() {
+			if operation.Security == nil {
+				operation.Security = swagger.Security
+			}
+		}
+	}
+}
+
+func containsAny(l, values []string) bool {
+	for _, value := range values {
+		if contains(l, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// hoistSchemas moves all inline objects of definitions, body parameters and
+// responses to the definitions.
+func hoistSchemas(swagger *Swagger) {
+	for _, name := range sortedKeys(swagger.Definitions) {
+		hoistProperties(swagger, name, swagger.Definitions[name])
+	}
+
+	for _, lpath := range sortedKeys(swagger.Paths) {
+		for _, operation := range swagger.Paths[lpath].operations() {
+			if operation.OperationID == "" {
+				continue
+			}
+
+			for _, parameter := range operation.Parameters {
+				name := export(operation.OperationID) + export(parameter.Name)
+				switch {
+				case parameter.In == "body" && parameter.Schema != nil:
+					parameter.Schema = hoist(swagger, export(operation.OperationID)+"Body", parameter.Schema)
+				case parameter.In == "body" || parameter.In == "formData":
+				case len(parameter.Enum) > 0 && parameter.Schema == nil:
+					parameter.Schema = hoist(swagger, name, &Schema{
+						Type:        parameter.Type,
+						Format:      parameter.Format,
+						Description: parameter.Description,
+						Enum:        parameter.Enum,
+					})
+					parameter.Type, parameter.Format = "", ""
+				case parameter.Type == "array" && parameter.Items != nil:
+					parameter.Items = hoist(swagger, name+"Item", parameter.Items)
+				}
+			}
+
+			for _, code := range sortedKeys(operation.Responses) {
+				response := operation.Responses[code]
+				if response.Schema == nil {
+					continue
+				}
+				name := export(operation.OperationID) + "Result"
+				if code != "200" {
+					name = export(operation.OperationID) + responseName(code) + "Result"
+				}
+				response.Schema = hoist(swagger, name, response.Schema)
+			}
+		}
+	}
+}
+
+// hoist moves an inline object schema, or the inline object items of an
+// array schema, to the definitions and returns a reference to it.
+func hoist(swagger *Swagger, name string, s *Schema) *Schema {
+	if len(s.AllOf) == 1 && s.AllOf[0].Ref != "" && len(s.Properties) == 0 {
+		return &Schema{Ref: s.AllOf[0].Ref, Description: s.Description}
+	}
+
+	if isInlineEnum(s) {
+		name = definitionName(swagger, name)
+		swagger.Definitions[name] = s
+		return &Schema{Ref: "#/definitions/" + name}
+	}
+
+	if !isInlineObject(s) {
+		hoistProperties(swagger, name, s)
+		return s
+	}
+
+	name = definitionName(swagger, name)
+	swagger.Definitions[name] = s
+	hoistProperties(swagger, name, s)
+	return &Schema{Ref: "#/definitions/" + name}
+}
+
+// hoistProperties hoists the inline objects in the properties, items and
+// additional properties of the schema name.
+func hoistProperties(swagger *Swagger, name string, s *Schema) {
+	switch {
+	case s.Type == "array" && s.Items != nil:
+		s.Items = hoist(swagger, name+"Item", s.Items)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		s.AdditionalProperties = hoist(swagger, name+"Value", s.AdditionalProperties)
+	}
+
+	for _, property := range sortedKeys(s.Properties) {
+		s.Properties[property] = hoist(swagger, name+export(property), s.Properties[property])
+	}
+
+	// the properties of inline allOf schemas become fields of the same struct
+	for _, schema := range s.AllOf {
+		if schema.Ref == "" {
+			hoistProperties(swagger, name, schema)
+		}
+	}
+}
+
+func isInlineObject(s *Schema) bool {
+	if s.Ref != "" {
+		return false
+	}
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		return true
+	}
+	return (s.Type == "object" || s.Type == "") && (len(s.Properties) > 0 || len(s.AllOf) > 0)
+}
+
+func isInlineEnum(s *Schema) bool {
+	return s.Ref == "" && isEnum(s)
+}
+
+// definitionName returns name, or name with a numeric suffix if a definition
+// with that name already exists.
+func definitionName(swagger *Swagger, name string) string {
+	if _, ok := swagger.Definitions[name]; !ok {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := name + strconv.Itoa(i)
+		if _, ok := swagger.Definitions[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// inlineAliases replaces the references to definitions that get no named
+// type, like primitive schemas, with a copy of the definition. The
+// description of the reference is kept.
+func inlineAliases(swagger *Swagger) {
+	visitSchemas(swagger, func(s *Schema) {
+		seen := map[string]bool{}
+		for s.Ref != "" {
+			name := path.Base(s.Ref)
+			definition, ok := swagger.Definitions[name]
+			if !ok || seen[name] || !isAlias(definition) {
+				return
+			}
+			seen[name] = true
+
+			description := s.Description
+			*s = *definition
+			if description != "" {
+				s.Description = description
+			}
+		}
+	})
+}
+
+// isAlias returns true if no named type is generated for the definition,
+// which is the case for primitive schemas and references.
+func isAlias(s *Schema) bool {
+	if s.Ref != "" {
+		return true
+	}
+	switch s.Type {
+	case "string", "number", "integer", "boolean":
+		return !isEnum(s)
+	}
+	return false
+}
+
+// unions converts Swagger 2.0 discriminator base definitions into oneOf
+// schemas and completes the discriminator mapping of all oneOf and anyOf
+// definitions. The oneOf schemas are restricted to the mapped discriminator
+// values, so the JSON schema validation is not ambiguous.
+func unions(swagger *Swagger) {
+	for _, name := range sortedKeys(swagger.Definitions) {
+		s := swagger.Definitions[name]
+		if s.Discriminator == nil {
+			continue
+		}
+
+		if len(s.OneOf) == 0 && len(s.AnyOf) == 0 {
+			s = inheritance(swagger, name, s)
+		}
+
+		mapping := map[string]string{}
+		for value, ref := range s.Discriminator.Mapping {
+			if !strings.Contains(ref, "/") {
+				ref = "#/definitions/" + ref
+			}
+			mapping[value] = ref
+		}
+		// variants without explicit mapping are selected by their name
+		for _, schema := range append(s.OneOf, s.AnyOf...) {
+			if schema.Ref != "" && !mapped(mapping, path.Base(schema.Ref)) {
+				mapping[path.Base(schema.Ref)] = schema.Ref
+			}
+		}
+		if len(mapping) == 0 {
+			continue
+		}
+		s.Discriminator.Mapping = mapping
+
+		s.OneOf, s.AnyOf = nil, nil
+		for _, variant := range variants(s) {
+			var values []interface{}
+			for _, value := range variant.Values {
+				values = append(values, value)
+			}
+			s.OneOf = append(s.OneOf, &Schema{
+				AllOf:      []*Schema{{Ref: "#/definitions/" + variant.Name}},
+				Properties: map[string]*Schema{s.Discriminator.PropertyName: {Enum: values}},
+				Required:   []string{s.Discriminator.PropertyName},
+			})
+		}
+	}
+}
+
+// inheritance converts a Swagger 2.0 discriminator base definition into a
+// oneOf schema of all definitions that extend it via allOf. The properties of
+// the base definition move to a new definition with the suffix Base.
+func inheritance(swagger *Swagger, name string, base *Schema) *Schema {
+	ref := "#/definitions/" + name
+
+	var subtypes []string
+	for _, subtype := range sortedKeys(swagger.Definitions) {
+		for _, schema := range swagger.Definitions[subtype].AllOf {
+			if schema.Ref == ref {
+				subtypes = append(subtypes, subtype)
+			}
+		}
+	}
+	if len(subtypes) == 0 {
+		return base
+	}
+
+	baseName := definitionName(swagger, name+"Base")
+	baseSchema := *base
+	baseSchema.Discriminator = nil
+	swagger.Definitions[baseName] = &baseSchema
+
+	union := &Schema{
+		Description:   base.Description,
+		Discriminator: &Discriminator{PropertyName: base.Discriminator.PropertyName, Mapping: map[string]string{}},
+	}
+	for _, subtype := range subtypes {
+		s := swagger.Definitions[subtype]
+		for _, schema := range s.AllOf {
+			if schema.Ref == ref {
+				schema.Ref = "#/definitions/" + baseName
+			}
+		}
+
+		value := s.DiscriminatorValue
+		if value == "" {
+			value = subtype
+		}
+		union.Discriminator.Mapping[value] = "#/definitions/" + subtype
+		union.OneOf = append(union.OneOf, &Schema{Ref: "#/definitions/" + subtype})
+	}
+
+	swagger.Definitions[name] = union
+	return union
+}
+
+type variant struct {
+	Name   string
+	Values []string
+}
+
+// variants returns the types of a discriminated union with their
+// discriminator values.
+func variants(s *Schema) []*variant {
+	if s.Discriminator == nil {
+		return nil
+	}
+
+	index := map[string]*variant{}
+	for _, value := range sortedKeys(s.Discriminator.Mapping) {
+		name := path.Base(s.Discriminator.Mapping[value])
+		if _, ok := index[name]; !ok {
+			index[name] = &variant{Name: name}
+		}
+		index[name].Values = append(index[name].Values, value)
+	}
+
+	var vs []*variant
+	for _, name := range sortedKeys(index) {
+		vs = append(vs, index[name])
+	}
+	sort.Slice(vs, func(i, j int) bool { return vs[i].Name < vs[j].Name })
+	return vs
+}
+
+// mapped returns true if a value of the discriminator mapping selects the
+// definition with the name.
+func mapped(mapping map[string]string, name string) bool {
+	for _, ref := range mapping {
+		if path.Base(ref) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// link sets the definition of every reference.
+func link(swagger *Swagger) {
+	visitSchemas(swagger, func(s *Schema) {
+		if s.Ref != "" {
+			s.resolved = swagger.Definitions[path.Base(s.Ref)]
+		}
+	})
+}
+
+// visitSchemas calls fn for every schema of the document.
+func visitSchemas(swagger *Swagger, fn func(*Schema)) {
+	var visit func(s *Schema)
+	visit = func(s *Schema) {
+		if s == nil {
+			return
+		}
+		fn(s)
+		visit(s.Items)
+		visit(s.AdditionalProperties)
+		for _, name := range sortedKeys(s.Properties) {
+			visit(s.Properties[name])
+		}
+		for _, schemas := range [][]*Schema{s.AllOf, s.OneOf, s.AnyOf} {
+			for _, schema := range schemas {
+				visit(schema)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(swagger.Definitions) {
+		visit(swagger.Definitions[name])
+	}
+	for _, lpath := range sortedKeys(swagger.Paths) {
+		for _, operation := range swagger.Paths[lpath].operations() {
+			for _, parameter := range operation.Parameters {
+				visit(parameter.Schema)
+				visit(parameter.Items)
+			}
+			for _, code := range sortedKeys(operation.Responses) {
+				visit(operation.Responses[code].Schema)
+			}
+		}
+	}
+}
+
+// warnFormats logs the formats that have no Go type, those fall back to the
+// type without format.
+func warnFormats(swagger *Swagger) {
+	warned := map[string]bool{}
+	warn := func(typ, format string) {
+		if !knownFormat(typ, format) && !warned[typ+"/"+format] {
+			warned[typ+"/"+format] = true
+			log.Printf("unknown format %s of type %s, using %s", format, typ, formats[typ][""])
+		}
+	}
+
+	visitSchemas(swagger, func(s *Schema) {
+		warn(s.Type, s.Format)
+	})
+	for _, lpath := range sortedKeys(swagger.Paths) {
+		for _, operation := range swagger.Paths[lpath].operations() {
+			for _, parameter := range operation.Parameters {
+				warn(parameter.Type, parameter.Format)
+			}
+		}
+	}
+}
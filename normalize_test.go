@@ -0,0 +1,179 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_hoist(t *testing.T) {
+	type args struct {
+		definitions []string
+		name        string
+		s           *Schema
+	}
+	tests := []struct {
+		name            string
+		args            args
+		want            *Schema
+		wantDefinitions []string
+	}{
+		{"string", args{nil, "UserName", &Schema{Type: "string"}}, &Schema{Type: "string"}, nil},
+		{"ref", args{nil, "UserManager", &Schema{Ref: "#/definitions/User"}}, &Schema{Ref: "#/definitions/User"}, nil},
+		{"empty object", args{nil, "UserMetadata", &Schema{Type: "object"}}, &Schema{Type: "object"}, nil},
+		{"object", args{nil, "UserAddress", &Schema{Type: "object", Properties: map[string]*Schema{"street": {Type: "string"}}}}, &Schema{Ref: "#/definitions/UserAddress"}, []string{"UserAddress"}},
+		{"nested object", args{nil, "UserAddress", &Schema{Type: "object", Properties: map[string]*Schema{"geo": {Properties: map[string]*Schema{"lat": {Type: "number"}}}}}}, &Schema{Ref: "#/definitions/UserAddress"}, []string{"UserAddress", "UserAddressGeo"}},
+		{"array", args{nil, "UserTags", &Schema{Type: "array", Items: &Schema{Type: "object", Properties: map[string]*Schema{"name": {Type: "string"}}}}}, &Schema{Type: "array", Items: &Schema{Ref: "#/definitions/UserTagsItem"}}, []string{"UserTagsItem"}},
+		{"map", args{nil, "UserLabels", &Schema{Type: "object", AdditionalProperties: &Schema{Type: "object", Properties: map[string]*Schema{"value": {Type: "string"}}}}}, &Schema{Type: "object", AdditionalProperties: &Schema{Ref: "#/definitions/UserLabelsValue"}}, []string{"UserLabelsValue"}},
+		{"enum", args{nil, "UserRole", &Schema{Type: "string", Enum: []interface{}{"admin"}}}, &Schema{Ref: "#/definitions/UserRole"}, []string{"UserRole"}},
+		{"enum items", args{nil, "UserTags", &Schema{Type: "array", Items: &Schema{Type: "string", Enum: []interface{}{"vip"}}}}, &Schema{Type: "array", Items: &Schema{Ref: "#/definitions/UserTagsItem"}}, []string{"UserTagsItem"}},
+		{"existing name", args{[]string{"User"}, "User", &Schema{Type: "object", Properties: map[string]*Schema{"name": {Type: "string"}}}}, &Schema{Ref: "#/definitions/User2"}, []string{"User", "User2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swagger := &Swagger{Definitions: map[string]*Schema{}}
+			for _, name := range tt.args.definitions {
+				swagger.Definitions[name] = &Schema{Type: "object"}
+			}
+
+			assert.Equalf(t, tt.want, hoist(swagger, tt.args.name, tt.args.s), "hoist(%v, %v)", tt.args.name, tt.args.s)
+			assert.ElementsMatch(t, tt.wantDefinitions, sortedKeys(swagger.Definitions))
+		})
+	}
+}
+
+func Test_inlineAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *Schema
+		want *Schema
+	}{
+		{"primitive", &Schema{Ref: "#/definitions/UserID"}, &Schema{Type: "string", Format: "uuid"}},
+		{"description", &Schema{Ref: "#/definitions/UserID", Description: "The owner."}, &Schema{Type: "string", Format: "uuid", Description: "The owner."}},
+		{"reference", &Schema{Ref: "#/definitions/OwnerID"}, &Schema{Type: "string", Format: "uuid"}},
+		{"object", &Schema{Ref: "#/definitions/User"}, &Schema{Ref: "#/definitions/User"}},
+		{"enum", &Schema{Ref: "#/definitions/Role"}, &Schema{Ref: "#/definitions/Role"}},
+		{"cycle", &Schema{Ref: "#/definitions/Loop"}, &Schema{Ref: "#/definitions/Loop"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swagger := &Swagger{Definitions: map[string]*Schema{
+				"UserID":  {Type: "string", Format: "uuid"},
+				"OwnerID": {Ref: "#/definitions/UserID"},
+				"User":    {Type: "object", Properties: map[string]*Schema{"id": tt.s}},
+				"Role":    {Type: "string", Enum: []interface{}{"admin"}},
+				"Loop":    {Ref: "#/definitions/Loop"},
+			}}
+
+			inlineAliases(swagger)
+			assert.Equal(t, tt.want, swagger.Definitions["User"].Properties["id"])
+		})
+	}
+}
+
+func Test_unions(t *testing.T) {
+	tests := []struct {
+		name         string
+		definitions  map[string]*Schema
+		want         string
+		wantVariants []*variant
+		wantBase     bool
+	}{
+		{
+			name: "swagger 2.0 inheritance",
+			definitions: map[string]*Schema{
+				"Pet": {Type: "object", Discriminator: &Discriminator{PropertyName: "petType"}, Properties: map[string]*Schema{"petType": {Type: "string"}}},
+				"Cat": {AllOf: []*Schema{{Ref: "#/definitions/Pet"}}},
+				"Dog": {AllOf: []*Schema{{Ref: "#/definitions/Pet"}}, DiscriminatorValue: "dog"},
+			},
+			want:         "Pet",
+			wantVariants: []*variant{{Name: "Cat", Values: []string{"Cat"}}, {Name: "Dog", Values: []string{"dog"}}},
+			wantBase:     true,
+		},
+		{
+			name: "implicit mapping",
+			definitions: map[string]*Schema{
+				"Shape":  {OneOf: []*Schema{{Ref: "#/definitions/Circle"}, {Ref: "#/definitions/Square"}}, Discriminator: &Discriminator{PropertyName: "kind", Mapping: map[string]string{"box": "Square"}}},
+				"Circle": {Type: "object"},
+				"Square": {Type: "object"},
+			},
+			want:         "Shape",
+			wantVariants: []*variant{{Name: "Circle", Values: []string{"Circle"}}, {Name: "Square", Values: []string{"box"}}},
+		},
+		{
+			name: "mapped variant",
+			definitions: map[string]*Schema{
+				"Pet": {OneOf: []*Schema{{Ref: "#/definitions/Cat"}}, Discriminator: &Discriminator{PropertyName: "kind", Mapping: map[string]string{"cat": "#/definitions/Cat"}}},
+				"Cat": {Type: "object"},
+			},
+			want:         "Pet",
+			wantVariants: []*variant{{Name: "Cat", Values: []string{"cat"}}},
+		},
+		{
+			name: "no subtypes",
+			definitions: map[string]*Schema{
+				"Pet": {Type: "object", Discriminator: &Discriminator{PropertyName: "petType"}},
+			},
+			want: "Pet",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swagger := &Swagger{Definitions: tt.definitions}
+			unions(swagger)
+
+			s := swagger.Definitions[tt.want]
+			assert.Equal(t, tt.wantVariants, variants(s))
+			assert.Len(t, s.OneOf, len(tt.wantVariants))
+			_, ok := swagger.Definitions[tt.want+"Base"]
+			assert.Equal(t, tt.wantBase, ok)
+		})
+	}
+}
+
+func Test_mediaTypes(t *testing.T) {
+	tests := []struct {
+		name         string
+		swagger      *Swagger
+		operation    *Operation
+		wantConsumes []string
+		wantProduces []string
+	}{
+		{"default", &Swagger{}, &Operation{}, []string{"application/json"}, []string{"application/json"}},
+		{"document", &Swagger{Consumes: []string{"application/xml"}, Produces: []string{"text/plain"}}, &Operation{}, []string{"application/xml"}, []string{"text/plain"}},
+		{"operation", &Swagger{Produces: []string{"text/plain"}}, &Operation{Produces: []string{"application/yaml"}}, []string{"application/json"}, []string{"application/yaml"}},
+		{"form", &Swagger{}, &Operation{Parameters: []*Parameter{{Name: "name", In: "formData"}}}, []string{"multipart/form-data", "application/x-www-form-urlencoded"}, []string{"application/json"}},
+		{"urlencoded form", &Swagger{}, &Operation{Consumes: []string{"application/x-www-form-urlencoded"}, Parameters: []*Parameter{{Name: "name", In: "formData"}}}, []string{"application/x-www-form-urlencoded"}, []string{"application/json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.swagger.Paths = map[string]*PathItem{"/a": {Get: tt.operation}}
+			mediaTypes(tt.swagger)
+
+			assert.Equal(t, tt.wantConsumes, tt.operation.Consumes)
+			assert.Equal(t, tt.wantProduces, tt.operation.Produces)
+		})
+	}
+}
+
+func Test_security(t *testing.T) {
+	global := []SecurityRequirement{{"oauth2": {"read"}}}
+	tests := []struct {
+		name      string
+		operation *Operation
+		want      []SecurityRequirement
+	}{
+		{"inherited", &Operation{}, global},
+		{"operation", &Operation{Security: []SecurityRequirement{{"apiKey": nil}}}, []SecurityRequirement{{"apiKey": nil}}},
+		{"public", &Operation{Security: []SecurityRequirement{}}, []SecurityRequirement{}},
+		{"anonymous", &Operation{Security: []SecurityRequirement{{"apiKey": nil}, {}}}, []SecurityRequirement{{"apiKey": nil}, {}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swagger := &Swagger{Security: global, Paths: map[string]*PathItem{"/a": {Get: tt.operation}}}
+			security(swagger)
+
+			assert.Equal(t, tt.want, tt.operation.Security)
+		})
+	}
+}
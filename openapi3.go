@@ -0,0 +1,708 @@
+package main
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPI is an OpenAPI 3.0 document. It is converted to the Swagger 2.0
+// model by convertOpenAPI, so the templates only need to know one format.
+type OpenAPI struct {
+	OpenAPI    string                      `yaml:"openapi"`
+	Info       *Info                       `yaml:"info"`
+	Servers    []*Server                   `yaml:"servers"`
+	Paths      map[string]*OpenAPIPathItem `yaml:"paths"`
+	Components *Components                 `yaml:"components"`
+	Security   []SecurityRequirement       `yaml:"security"`
+}
+
+type Server struct {
+	URL       string                     `yaml:"url"`
+	Variables map[string]*ServerVariable `yaml:"variables"`
+
+	node *yaml.Node
+}
+
+func (s *Server) UnmarshalYAML(value *yaml.Node) error {
+	type plain Server
+	s.node = value
+	return value.Decode((*plain)(s))
+}
+
+type ServerVariable struct {
+	Default string `yaml:"default"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema                `yaml:"schemas"`
+	Parameters      map[string]*OpenAPIParameter      `yaml:"parameters"`
+	RequestBodies   map[string]*RequestBody           `yaml:"requestBodies"`
+	Responses       map[string]*OpenAPIResponse       `yaml:"responses"`
+	Headers         map[string]*OpenAPIHeader         `yaml:"headers"`
+	SecuritySchemes map[string]*OpenAPISecurityScheme `yaml:"securitySchemes"`
+}
+
+type OpenAPIPathItem struct {
+	Parameters []*OpenAPIParameter `yaml:"parameters"`
+	Get        *OpenAPIOperation   `yaml:"get"`
+	Post       *OpenAPIOperation   `yaml:"post"`
+	Put        *OpenAPIOperation   `yaml:"put"`
+	Patch      *OpenAPIOperation   `yaml:"patch"`
+	Delete     *OpenAPIOperation   `yaml:"delete"`
+}
+
+type OpenAPIOperation struct {
+	Tags            []string                    `yaml:"tags"`
+	Summary         string                      `yaml:"summary"`
+	Description     string                      `yaml:"description"`
+	OperationID     string                      `yaml:"operationId"`
+	Parameters      []*OpenAPIParameter         `yaml:"parameters"`
+	RequestBody     *RequestBody                `yaml:"requestBody"`
+	RequestBodyName string                      `yaml:"x-codegen-request-body-name"`
+	Responses       map[string]*OpenAPIResponse `yaml:"responses"`
+	Security        []SecurityRequirement       `yaml:"security"`
+	MaxBodySize     int64                       `yaml:"x-max-body-size"`
+
+	node *yaml.Node
+}
+
+func (o *OpenAPIOperation) UnmarshalYAML(value *yaml.Node) error {
+	type plain OpenAPIOperation
+	o.node = value
+	return value.Decode((*plain)(o))
+}
+
+type OpenAPIParameter struct {
+	Ref         string      `yaml:"$ref"`
+	Name        string      `yaml:"name"`
+	In          string      `yaml:"in"`
+	Description string      `yaml:"description"`
+	Required    bool        `yaml:"required"`
+	Style       string      `yaml:"style"`
+	Explode     *bool       `yaml:"explode"`
+	Schema      *Schema     `yaml:"schema"`
+	Example     interface{} `yaml:"example"`
+
+	node *yaml.Node
+}
+
+func (p *OpenAPIParameter) UnmarshalYAML(value *yaml.Node) error {
+	type plain OpenAPIParameter
+	p.node = value
+	return value.Decode((*plain)(p))
+}
+
+type RequestBody struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Required    bool                  `yaml:"required"`
+	Content     map[string]*MediaType `yaml:"content"`
+
+	node *yaml.Node
+}
+
+func (b *RequestBody) UnmarshalYAML(value *yaml.Node) error {
+	type plain RequestBody
+	b.node = value
+	return value.Decode((*plain)(b))
+}
+
+type OpenAPIResponse struct {
+	Ref         string                    `yaml:"$ref"`
+	Description string                    `yaml:"description"`
+	Headers     map[string]*OpenAPIHeader `yaml:"headers"`
+	Content     map[string]*MediaType     `yaml:"content"`
+
+	node *yaml.Node
+}
+
+func (r *OpenAPIResponse) UnmarshalYAML(value *yaml.Node) error {
+	type plain OpenAPIResponse
+	r.node = value
+	return value.Decode((*plain)(r))
+}
+
+type OpenAPIHeader struct {
+	Ref         string  `yaml:"$ref"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Schema      *Schema `yaml:"schema"`
+
+	node *yaml.Node
+}
+
+func (h *OpenAPIHeader) UnmarshalYAML(value *yaml.Node) error {
+	type plain OpenAPIHeader
+	h.node = value
+	return value.Decode((*plain)(h))
+}
+
+type MediaType struct {
+	Schema   *Schema             `yaml:"schema"`
+	Example  interface{}         `yaml:"example"`
+	Examples map[string]*Example `yaml:"examples"`
+}
+
+type Example struct {
+	Value interface{} `yaml:"value"`
+}
+
+type OpenAPISecurityScheme struct {
+	Type             string      `yaml:"type"`
+	Description      string      `yaml:"description"`
+	Name             string      `yaml:"name"`
+	In               string      `yaml:"in"`
+	Scheme           string      `yaml:"scheme"`
+	Flows            *OAuthFlows `yaml:"flows"`
+	OpenIDConnectURL string      `yaml:"openIdConnectUrl"`
+}
+
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `yaml:"implicit"`
+	Password          *OAuthFlow `yaml:"password"`
+	ClientCredentials *OAuthFlow `yaml:"clientCredentials"`
+	AuthorizationCode *OAuthFlow `yaml:"authorizationCode"`
+}
+
+type OAuthFlow struct {
+	AuthorizationURL string            `yaml:"authorizationUrl"`
+	TokenURL         string            `yaml:"tokenUrl"`
+	Scopes           map[string]string `yaml:"scopes"`
+}
+
+const componentSchemas = "#/components/schemas/"
+
+// convertOpenAPI converts an OpenAPI 3.0 document to Swagger 2.0. Operations
+// that cannot be converted are left out and their problems are returned
+// with the converted document.
+func convertOpenAPI(o *OpenAPI) (*Swagger, SpecErrors) {
+	if o.Components == nil {
+		o.Components = &Components{}
+	}
+
+	swagger := &Swagger{
+		Swagger:     "2.0",
+		Info:        o.Info,
+		Paths:       map[string]*PathItem{},
+		Definitions: map[string]*Schema{},
+		Security:    o.Security,
+	}
+
+	var errs SpecErrors
+	if err := convertServers(swagger, o.Servers); err != nil {
+		errs = append(errs, asSpecError(err, nil))
+	}
+
+	for name, schema := range o.Components.Schemas {
+		swagger.Definitions[name] = rewriteRefs(schema)
+	}
+
+	for name, scheme := range o.Components.SecuritySchemes {
+		if swagger.SecurityDefinitions == nil {
+			swagger.SecurityDefinitions = map[string]*SecurityScheme{}
+		}
+		swagger.SecurityDefinitions[name] = convertSecurityScheme(scheme)
+	}
+
+	for _, lpath := range sortedKeys(o.Paths) {
+		item := o.Paths[lpath]
+		pathItem := &PathItem{}
+		for _, op := range []struct {
+			src *OpenAPIOperation
+			dst **Operation
+		}{
+			{item.Get, &pathItem.Get},
+			{item.Post, &pathItem.Post},
+			{item.Put, &pathItem.Put},
+			{item.Patch, &pathItem.Patch},
+			{item.Delete, &pathItem.Delete},
+		} {
+			if op.src == nil {
+				continue
+			}
+
+			operation, err := convertOperation(o, item.Parameters, op.src)
+			if err != nil {
+				errs = append(errs, asSpecError(err, op.src.node))
+				continue
+			}
+			*op.dst = operation
+		}
+		swagger.Paths[lpath] = pathItem
+	}
+
+	return swagger, errs
+}
+
+func convertServers(swagger *Swagger, servers []*Server) error {
+	for i, server := range servers {
+		rawURL := server.URL
+		for name, variable := range server.Variables {
+			rawURL = strings.ReplaceAll(rawURL, "{"+name+"}", variable.Default)
+		}
+
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return errorf(server.node, "invalid server url %q: %s", server.URL, err)
+		}
+
+		if i == 0 {
+			swagger.Host = u.Host
+			swagger.BasePath = u.Path
+		}
+		if u.Scheme != "" && !contains(swagger.Schemes, u.Scheme) {
+			swagger.Schemes = append(swagger.Schemes, u.Scheme)
+		}
+	}
+	return nil
+}
+
+func convertOperation(o *OpenAPI, pathParameters []*OpenAPIParameter, src *OpenAPIOperation) (*Operation, error) {
+	operation := &Operation{
+		Tags:        src.Tags,
+		Summary:     src.Summary,
+		Description: src.Description,
+		OperationID: src.OperationID,
+		Responses:   map[string]*Response{},
+		Security:    src.Security,
+		MaxBodySize: src.MaxBodySize,
+		node:        src.node,
+	}
+
+	parameters, err := mergeParameters(o, pathParameters, src.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	for _, parameter := range parameters {
+		operation.Parameters = append(operation.Parameters, convertParameter(parameter))
+	}
+
+	if src.RequestBody != nil {
+		bodyParameters, err := convertRequestBody(o, src.RequestBodyName, src.RequestBody)
+		if err != nil {
+			return nil, err
+		}
+		operation.Parameters = append(operation.Parameters, bodyParameters...)
+		body, err := resolveRequestBody(o, src.RequestBody)
+		if err != nil {
+			return nil, err
+		}
+		operation.Consumes = contentTypes(body.Content)
+	}
+
+	produces := map[string]*MediaType{}
+
+	for code, response := range src.Responses {
+		if response.Ref != "" {
+			resolved, ok := o.Components.Responses[path.Base(response.Ref)]
+			if !ok {
+				return nil, errorf(response.node, "unknown response %s", response.Ref)
+			}
+			response = resolved
+		}
+		converted, err := convertResponse(o, response)
+		if err != nil {
+			return nil, err
+		}
+		operation.Responses[code] = converted
+
+		for contentType, mediaType := range response.Content {
+			produces[contentType] = mediaType
+		}
+	}
+	operation.Produces = contentTypes(produces)
+
+	return operation, nil
+}
+
+// mergeParameters combines path level and operation level parameters, where
+// operation level parameters override path level parameters with the same
+// name and location.
+func mergeParameters(o *OpenAPI, pathParameters, operationParameters []*OpenAPIParameter) ([]*OpenAPIParameter, error) {
+	var parameters []*OpenAPIParameter
+	index := map[string]int{}
+	all := append(append([]*OpenAPIParameter{}, pathParameters...), operationParameters...)
+	for _, parameter := range all {
+		if parameter.Ref != "" {
+			resolved, ok := o.Components.Parameters[path.Base(parameter.Ref)]
+			if !ok {
+				return nil, errorf(parameter.node, "unknown parameter %s", parameter.Ref)
+			}
+			parameter = resolved
+		}
+
+		key := parameter.In + "/" + parameter.Name
+		if i, ok := index[key]; ok {
+			parameters[i] = parameter
+			continue
+		}
+		index[key] = len(parameters)
+		parameters = append(parameters, parameter)
+	}
+	return parameters, nil
+}
+
+func convertParameter(src *OpenAPIParameter) *Parameter {
+	parameter := &Parameter{
+		Name:        src.Name,
+		In:          src.In,
+		Description: src.Description,
+		Required:    src.Required,
+		Examples:    src.Example,
+		node:        src.node,
+	}
+
+	if src.Schema == nil {
+		parameter.Type = "string"
+		return parameter
+	}
+
+	if src.Schema.Ref != "" {
+		parameter.Schema = rewriteRefs(src.Schema)
+		return parameter
+	}
+
+	schemaParameter(parameter, src.Schema)
+	if parameter.Type == "array" {
+		parameter.CollectionFormat = collectionFormat(src)
+	}
+
+	return parameter
+}
+
+// schemaParameter sets the type and the validation keywords of a parameter
+// to the ones of its schema.
+func schemaParameter(parameter *Parameter, s *Schema) {
+	parameter.Type = s.Type
+	parameter.Format = s.Format
+	parameter.Items = rewriteRefs(s.Items)
+	parameter.Default = s.Default
+	parameter.Maximum = s.Maximum
+	parameter.Minimum = s.Minimum
+	parameter.ExclusiveMinimum = s.ExclusiveMinimum
+	parameter.ExclusiveMaximum = s.ExclusiveMaximum
+	parameter.MultipleOf = s.MultipleOf
+	parameter.MinLength = s.MinLength
+	parameter.MaxLength = s.MaxLength
+	parameter.Pattern = s.Pattern
+	parameter.MinItems = s.MinItems
+	parameter.MaxItems = s.MaxItems
+	parameter.UniqueItems = s.UniqueItems
+	parameter.Enum = s.Enum
+}
+
+// collectionFormat returns the Swagger 2.0 collection format of the style
+// of an array parameter. Exploded query and cookie parameters are repeated
+// for every item.
+func collectionFormat(p *OpenAPIParameter) string {
+	if p.In == "path" || p.In == "header" {
+		return "csv"
+	}
+
+	explode := p.Style == "" || p.Style == "form"
+	if p.Explode != nil {
+		explode = *p.Explode
+	}
+
+	switch {
+	case explode:
+		return "multi"
+	case p.Style == "spaceDelimited":
+		return "ssv"
+	case p.Style == "pipeDelimited":
+		return "pipes"
+	default:
+		return "csv"
+	}
+}
+
+func convertRequestBody(o *OpenAPI, name string, body *RequestBody) ([]*Parameter, error) {
+	body, err := resolveRequestBody(o, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = "body"
+	}
+
+	if mediaType, ok := jsonMediaType(body.Content); ok {
+		return []*Parameter{bodyParameter(name, body, mediaType)}, nil
+	}
+
+	for _, contentType := range []string{"multipart/form-data", "application/x-www-form-urlencoded"} {
+		if mediaType, ok := body.Content[contentType]; ok {
+			return formParameters(o, mediaType.Schema)
+		}
+	}
+
+	if mediaType, ok := firstMediaType(body.Content); ok {
+		return []*Parameter{bodyParameter(name, body, mediaType)}, nil
+	}
+
+	return nil, nil
+}
+
+// resolveRequestBody returns the request body a reference points to or the
+// body itself.
+func resolveRequestBody(o *OpenAPI, body *RequestBody) (*RequestBody, error) {
+	if body.Ref == "" {
+		return body, nil
+	}
+	resolved, ok := o.Components.RequestBodies[path.Base(body.Ref)]
+	if !ok {
+		return nil, errorf(body.node, "unknown request body %s", body.Ref)
+	}
+	return resolved, nil
+}
+
+// contentTypes returns the content types of a content map sorted, with
+// JSON first.
+func contentTypes(content map[string]*MediaType) []string {
+	var types []string
+	if _, ok := content["application/json"]; ok {
+		types = append(types, "application/json")
+	}
+	for _, contentType := range sortedKeys(content) {
+		if contentType != "application/json" {
+			types = append(types, contentType)
+		}
+	}
+	return types
+}
+
+func bodyParameter(name string, body *RequestBody, mediaType *MediaType) *Parameter {
+	parameter := &Parameter{
+		Name:        name,
+		In:          "body",
+		Description: body.Description,
+		Required:    body.Required,
+		Schema:      rewriteRefs(mediaType.Schema),
+		Examples:    mediaType.Example,
+	}
+	if example, ok := mediaType.Examples["test"]; ok && parameter.Examples == nil {
+		parameter.Examples = example.Value
+	}
+	if parameter.Schema == nil {
+		parameter.Schema = &Schema{}
+	}
+	return parameter
+}
+
+// formParameters converts the properties of a multipart/form-data or
+// application/x-www-form-urlencoded request body into formData parameters.
+func formParameters(o *OpenAPI, schema *Schema) ([]*Parameter, error) {
+	if schema == nil {
+		return nil, nil
+	}
+	if schema.Ref != "" {
+		resolved, ok := o.Components.Schemas[path.Base(schema.Ref)]
+		if !ok {
+			return nil, errorf(schema.node, "unknown schema %s", schema.Ref)
+		}
+		schema = resolved
+	}
+
+	var parameters []*Parameter
+	for _, name := range sortedKeys(schema.Properties) {
+		property := schema.Properties[name]
+		parameter := &Parameter{
+			Name:        name,
+			In:          "formData",
+			Description: property.Description,
+			Required:    contains(schema.Required, name),
+		}
+		schemaParameter(parameter, property)
+		if isBinary(property) || (property.Items != nil && isBinary(property.Items)) {
+			parameter.Type = "file"
+			parameter.Format = ""
+			parameter.Items = nil
+		}
+		parameters = append(parameters, parameter)
+	}
+	return parameters, nil
+}
+
+func isBinary(s *Schema) bool {
+	return s.Type == "string" && s.Format == "binary"
+}
+
+func convertResponse(o *OpenAPI, src *OpenAPIResponse) (*Response, error) {
+	response := &Response{Description: src.Description}
+
+	for name, header := range src.Headers {
+		converted, err := convertHeader(o, header)
+		if err != nil {
+			return nil, err
+		}
+		if response.Headers == nil {
+			response.Headers = map[string]*Header{}
+		}
+		response.Headers[name] = converted
+	}
+
+	mediaType, ok := jsonMediaType(src.Content)
+	if !ok {
+		mediaType, ok = firstMediaType(src.Content)
+	}
+	if !ok {
+		return response, nil
+	}
+
+	response.Schema = rewriteRefs(mediaType.Schema)
+	for name, example := range mediaType.Examples {
+		if response.Examples == nil {
+			response.Examples = map[string]interface{}{}
+		}
+		response.Examples[name] = example.Value
+	}
+
+	return response, nil
+}
+
+// convertHeader converts a response header, its schema is resolved as
+// headers only have primitive types.
+func convertHeader(o *OpenAPI, src *OpenAPIHeader) (*Header, error) {
+	if src.Ref != "" {
+		var resolved *OpenAPIHeader
+		if o.Components != nil {
+			resolved = o.Components.Headers[path.Base(src.Ref)]
+		}
+		if resolved == nil {
+			return nil, errorf(src.node, "unknown header %s", src.Ref)
+		}
+		src = resolved
+	}
+
+	header := &Header{Description: src.Description, Required: src.Required, Type: "string"}
+	schema := src.Schema
+	if schema != nil && schema.Ref != "" && o.Components != nil {
+		if resolved, ok := o.Components.Schemas[path.Base(schema.Ref)]; ok {
+			schema = resolved
+		}
+	}
+	if schema != nil && schema.Ref == "" {
+		header.Type = schema.Type
+		header.Format = schema.Format
+		header.Items = rewriteRefs(schema.Items)
+		header.Enum = schema.Enum
+	}
+	return header, nil
+}
+
+func convertSecurityScheme(src *OpenAPISecurityScheme) *SecurityScheme {
+	scheme := &SecurityScheme{
+		Type:        src.Type,
+		Description: src.Description,
+		Name:        src.Name,
+		In:          src.In,
+	}
+
+	switch src.Type {
+	case "http":
+		if strings.EqualFold(src.Scheme, "basic") {
+			scheme.Type = "basic"
+		} else {
+			scheme.Type = "apiKey"
+			scheme.Name = "Authorization"
+			scheme.In = "header"
+			scheme.Scheme = strings.ToLower(src.Scheme)
+		}
+	case "openIdConnect":
+		scheme.Type = "oauth2"
+		scheme.Flow = "accessCode"
+		scheme.OpenIDConnectURL = src.OpenIDConnectURL
+	case "oauth2":
+		if src.Flows == nil {
+			break
+		}
+		for _, flow := range []struct {
+			name string
+			flow *OAuthFlow
+		}{
+			{"accessCode", src.Flows.AuthorizationCode},
+			{"implicit", src.Flows.Implicit},
+			{"password", src.Flows.Password},
+			{"application", src.Flows.ClientCredentials},
+		} {
+			if flow.flow == nil {
+				continue
+			}
+			scheme.Flow = flow.name
+			scheme.AuthorizationURL = flow.flow.AuthorizationURL
+			scheme.TokenURL = flow.flow.TokenURL
+			scheme.Scopes = flow.flow.Scopes
+			break
+		}
+	}
+
+	return scheme
+}
+
+// jsonMediaType returns the JSON media type of a content map, preferring
+// application/json over other JSON based media types.
+func jsonMediaType(content map[string]*MediaType) (*MediaType, bool) {
+	if mediaType, ok := content["application/json"]; ok {
+		return mediaType, true
+	}
+	for _, contentType := range sortedKeys(content) {
+		if strings.HasSuffix(contentType, "+json") || contentType == "*/*" {
+			return content[contentType], true
+		}
+	}
+	return nil, false
+}
+
+// firstMediaType returns the media type with the alphabetically first content
+// type, so the conversion does not depend on map ordering.
+func firstMediaType(content map[string]*MediaType) (*MediaType, bool) {
+	keys := sortedKeys(content)
+	if len(keys) == 0 {
+		return nil, false
+	}
+	return content[keys[0]], true
+}
+
+// rewriteRefs changes all references to OpenAPI 3.0 components into
+// references to Swagger 2.0 definitions.
+func rewriteRefs(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+
+	if strings.HasPrefix(s.Ref, componentSchemas) {
+		s.Ref = "#/definitions/" + strings.TrimPrefix(s.Ref, componentSchemas)
+	}
+	rewriteRefs(s.Items)
+	rewriteRefs(s.AdditionalProperties)
+	for _, property := range s.Properties {
+		rewriteRefs(property)
+	}
+	for _, schemas := range [][]*Schema{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, schema := range schemas {
+			rewriteRefs(schema)
+		}
+	}
+	if s.Discriminator != nil {
+		for value, ref := range s.Discriminator.Mapping {
+			if strings.HasPrefix(ref, componentSchemas) {
+				s.Discriminator.Mapping[value] = "#/definitions/" + strings.TrimPrefix(ref, componentSchemas)
+			}
+		}
+	}
+
+	return s
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
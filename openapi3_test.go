@@ -0,0 +1,183 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func Test_convertParameter(t *testing.T) {
+	type args struct {
+		parameter *OpenAPIParameter
+	}
+	tests := []struct {
+		name string
+		args args
+		want *Parameter
+	}{
+		{"string", args{&OpenAPIParameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "string"}, Example: "123"}}, &Parameter{Name: "id", In: "path", Required: true, Type: "string", Examples: "123"}},
+		{"integer", args{&OpenAPIParameter{Name: "no", In: "query", Schema: &Schema{Type: "integer", Format: "int64"}}}, &Parameter{Name: "no", In: "query", Type: "integer", Format: "int64"}},
+		{"no schema", args{&OpenAPIParameter{Name: "q", In: "query"}}, &Parameter{Name: "q", In: "query", Type: "string"}},
+		{"ref", args{&OpenAPIParameter{Name: "user", In: "query", Schema: &Schema{Ref: "#/components/schemas/User"}}}, &Parameter{Name: "user", In: "query", Schema: &Schema{Ref: "#/definitions/User"}}},
+		{"array", args{&OpenAPIParameter{Name: "ids", In: "query", Schema: &Schema{Type: "array", Items: &Schema{Type: "integer"}}}}, &Parameter{Name: "ids", In: "query", Type: "array", Items: &Schema{Type: "integer"}, CollectionFormat: "multi"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, convertParameter(tt.args.parameter), "convertParameter(%v)", tt.args.parameter)
+		})
+	}
+}
+
+func Test_collectionFormat(t *testing.T) {
+	no := false
+
+	tests := []struct {
+		name      string
+		parameter *OpenAPIParameter
+		want      string
+	}{
+		{"form", &OpenAPIParameter{In: "query"}, "multi"},
+		{"form not exploded", &OpenAPIParameter{In: "query", Style: "form", Explode: &no}, "csv"},
+		{"space delimited", &OpenAPIParameter{In: "query", Style: "spaceDelimited"}, "ssv"},
+		{"pipe delimited", &OpenAPIParameter{In: "query", Style: "pipeDelimited"}, "pipes"},
+		{"header", &OpenAPIParameter{In: "header", Style: "simple"}, "csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, collectionFormat(tt.parameter), "collectionFormat(%v)", tt.parameter)
+		})
+	}
+}
+
+func Test_convertHeader(t *testing.T) {
+	o := &OpenAPI{Components: &Components{
+		Headers: map[string]*OpenAPIHeader{"RequestID": {Description: "Request id", Schema: &Schema{Type: "string", Format: "uuid"}}},
+		Schemas: map[string]*Schema{"Count": {Type: "integer", Format: "int64"}},
+	}}
+
+	tests := []struct {
+		name    string
+		header  *OpenAPIHeader
+		want    *Header
+		wantErr bool
+	}{
+		{"integer", &OpenAPIHeader{Required: true, Schema: &Schema{Type: "integer"}}, &Header{Required: true, Type: "integer"}, false},
+		{"no schema", &OpenAPIHeader{}, &Header{Type: "string"}, false},
+		{"ref", &OpenAPIHeader{Ref: "#/components/headers/RequestID"}, &Header{Description: "Request id", Type: "string", Format: "uuid"}, false},
+		{"schema ref", &OpenAPIHeader{Schema: &Schema{Ref: "#/components/schemas/Count"}}, &Header{Type: "integer", Format: "int64"}, false},
+		{"unknown ref", &OpenAPIHeader{Ref: "#/components/headers/Missing"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertHeader(o, tt.header)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_convertRequestBody(t *testing.T) {
+	one, three, sixtyFour := 1, 3, 64
+	type args struct {
+		name string
+		body *RequestBody
+	}
+	tests := []struct {
+		name string
+		args args
+		want []*Parameter
+	}{
+		{"json", args{"", &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/User"}}}}}, []*Parameter{{Name: "body", In: "body", Required: true, Schema: &Schema{Ref: "#/definitions/User"}}}},
+		{"named json", args{"user", &RequestBody{Content: map[string]*MediaType{"application/vnd.api+json": {Schema: &Schema{Type: "string"}}}}}, []*Parameter{{Name: "user", In: "body", Schema: &Schema{Type: "string"}}}},
+		{"multipart", args{"", &RequestBody{Content: map[string]*MediaType{"multipart/form-data": {Schema: &Schema{Type: "object", Required: []string{"file"}, Properties: map[string]*Schema{
+			"file": {Type: "string", Format: "binary"},
+			"name": {Type: "string"},
+		}}}}}}, []*Parameter{
+			{Name: "file", In: "formData", Required: true, Type: "file"},
+			{Name: "name", In: "formData", Type: "string"},
+		}},
+		{"form validation", args{"", &RequestBody{Content: map[string]*MediaType{"application/x-www-form-urlencoded": {Schema: &Schema{Type: "object", Properties: map[string]*Schema{
+			"name":     {Type: "string", MinLength: &one, MaxLength: &sixtyFour, Pattern: "^[a-z]+$"},
+			"priority": {Type: "integer", Minimum: 1, Maximum: 10, ExclusiveMaximum: true, MultipleOf: 2},
+			"tags":     {Type: "array", Items: &Schema{Type: "string"}, MinItems: &one, MaxItems: &three, UniqueItems: true},
+			"contact":  {Type: "string", Format: "email", Enum: []interface{}{"a@example.com"}, Default: "a@example.com"},
+		}}}}}}, []*Parameter{
+			{Name: "contact", In: "formData", Type: "string", Format: "email", Enum: []interface{}{"a@example.com"}, Default: "a@example.com"},
+			{Name: "name", In: "formData", Type: "string", MinLength: &one, MaxLength: &sixtyFour, Pattern: "^[a-z]+$"},
+			{Name: "priority", In: "formData", Type: "integer", Minimum: 1, Maximum: 10, ExclusiveMaximum: true, MultipleOf: 2},
+			{Name: "tags", In: "formData", Type: "array", Items: &Schema{Type: "string"}, MinItems: &one, MaxItems: &three, UniqueItems: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertRequestBody(&OpenAPI{Components: &Components{}}, tt.args.name, tt.args.body)
+			assert.NoError(t, err)
+			assert.Equalf(t, tt.want, got, "convertRequestBody(%v, %v)", tt.args.name, tt.args.body)
+		})
+	}
+}
+
+func Test_contentTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content map[string]*MediaType
+		want    []string
+	}{
+		{"empty", nil, nil},
+		{"json first", map[string]*MediaType{"text/plain": {}, "application/xml": {}, "application/json": {}}, []string{"application/json", "application/xml", "text/plain"}},
+		{"without json", map[string]*MediaType{"application/yaml": {}}, []string{"application/yaml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, contentTypes(tt.content), "contentTypes(%v)", tt.content)
+		})
+	}
+}
+
+func Test_convertSecurityScheme(t *testing.T) {
+	type args struct {
+		scheme *OpenAPISecurityScheme
+	}
+	tests := []struct {
+		name string
+		args args
+		want *SecurityScheme
+	}{
+		{"basic", args{&OpenAPISecurityScheme{Type: "http", Scheme: "basic"}}, &SecurityScheme{Type: "basic"}},
+		{"bearer", args{&OpenAPISecurityScheme{Type: "http", Scheme: "bearer"}}, &SecurityScheme{Type: "apiKey", Name: "Authorization", In: "header", Scheme: "bearer"}},
+		{"oauth2", args{&OpenAPISecurityScheme{Type: "oauth2", Flows: &OAuthFlows{AuthorizationCode: &OAuthFlow{AuthorizationURL: "https://a", TokenURL: "https://t", Scopes: map[string]string{"read": ""}}}}}, &SecurityScheme{Type: "oauth2", Flow: "accessCode", AuthorizationURL: "https://a", TokenURL: "https://t", Scopes: map[string]string{"read": ""}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, convertSecurityScheme(tt.args.scheme), "convertSecurityScheme(%v)", tt.args.scheme)
+		})
+	}
+}
+
+func Test_parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		yamlData string
+		wantErr  bool
+	}{
+		{"swagger", "swagger: \"2.0\"", false},
+		{"openapi 3.0", "openapi: 3.0.3", false},
+		{"openapi 3.1", "openapi: 3.1.0", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &yaml.Node{}
+			if err := yaml.Unmarshal([]byte(tt.yamlData), doc); err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err := parse(doc.Content[0])
+			assert.Equal(t, tt.wantErr, err != nil, "parse(%v) error = %v", tt.yamlData, err)
+		})
+	}
+}
@@ -1,10 +1,21 @@
 package main
 
+import "gopkg.in/yaml.v3"
+
 type Swagger struct {
-	Swagger     string               `yaml:"swagger" json:"swagger"`
-	Info        *Info                `yaml:"info" json:"info"`
-	Paths       map[string]*PathItem `yaml:"paths" json:"paths"`
-	Definitions map[string]*Schema   `yaml:"definitions" json:"definitions"`
+	Swagger             string                     `yaml:"swagger" json:"swagger"`
+	Info                *Info                      `yaml:"info" json:"info"`
+	Host                string                     `yaml:"host" json:"host,omitempty"`
+	BasePath            string                     `yaml:"basePath" json:"basePath,omitempty"`
+	Schemes             []string                   `yaml:"schemes" json:"schemes,omitempty"`
+	Consumes            []string                   `yaml:"consumes" json:"consumes,omitempty"`
+	Produces            []string                   `yaml:"produces" json:"produces,omitempty"`
+	Paths               map[string]*PathItem       `yaml:"paths" json:"paths"`
+	Definitions         map[string]*Schema         `yaml:"definitions" json:"definitions"`
+	SecurityDefinitions map[string]*SecurityScheme `yaml:"securityDefinitions" json:"securityDefinitions,omitempty"`
+	Security            []SecurityRequirement      `yaml:"security" json:"security,omitempty"`
+
+	node *yaml.Node // the source of the document for error messages
 }
 
 type Info struct {
@@ -20,14 +31,36 @@ type PathItem struct {
 	Delete *Operation `yaml:"delete" json:"delete"`
 }
 
+// operations returns the defined operations of the path item.
+func (p *PathItem) operations() []*Operation {
+	var operations []*Operation
+	for _, operation := range []*Operation{p.Get, p.Post, p.Put, p.Patch, p.Delete} {
+		if operation != nil {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
 type Operation struct {
-	Tags        []string             `yaml:"tags" json:"tags"`
-	Summary     string               `yaml:"summary" json:"summary"`
-	Description string               `yaml:"description" json:"description"`
-	OperationID string               `yaml:"operationId" json:"operationId"`
-	Parameters  []*Parameter         `yaml:"parameters" json:"parameters"`
-	Responses   map[string]*Response `yaml:"responses" json:"responses"`
-	Security    []*Security          `yaml:"security" json:"security"`
+	Tags        []string              `yaml:"tags" json:"tags"`
+	Summary     string                `yaml:"summary" json:"summary"`
+	Description string                `yaml:"description" json:"description"`
+	OperationID string                `yaml:"operationId" json:"operationId"`
+	Consumes    []string              `yaml:"consumes" json:"consumes,omitempty"`
+	Produces    []string              `yaml:"produces" json:"produces,omitempty"`
+	Parameters  []*Parameter          `yaml:"parameters" json:"parameters"`
+	Responses   map[string]*Response  `yaml:"responses" json:"responses"`
+	Security    []SecurityRequirement `yaml:"security" json:"security"`
+	MaxBodySize int64                 `yaml:"x-max-body-size" json:"x-max-body-size,omitempty"`
+
+	node *yaml.Node // the source of the operation for error messages
+}
+
+func (o *Operation) UnmarshalYAML(value *yaml.Node) error {
+	type plain Operation
+	o.node = value
+	return value.Decode((*plain)(o))
 }
 
 type Parameter struct {
@@ -44,17 +77,66 @@ type Parameter struct {
 	Default interface{} `yaml:"default,omitempty" json:"default,omitempty"`
 	Maximum interface{} `yaml:"maximum,omitempty" json:"maximum,omitempty"`
 
+	Minimum          interface{} `yaml:"minimum,omitempty" json:"minimum,omitempty"`
+	ExclusiveMinimum bool        `yaml:"exclusiveMinimum,omitempty" json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum bool        `yaml:"exclusiveMaximum,omitempty" json:"exclusiveMaximum,omitempty"`
+	MultipleOf       interface{} `yaml:"multipleOf,omitempty" json:"multipleOf,omitempty"`
+	MinLength        *int        `yaml:"minLength,omitempty" json:"minLength,omitempty"`
+	MaxLength        *int        `yaml:"maxLength,omitempty" json:"maxLength,omitempty"`
+	Pattern          string      `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	MinItems         *int        `yaml:"minItems,omitempty" json:"minItems,omitempty"`
+	MaxItems         *int        `yaml:"maxItems,omitempty" json:"maxItems,omitempty"`
+	UniqueItems      bool        `yaml:"uniqueItems,omitempty" json:"uniqueItems,omitempty"`
+	CollectionFormat string      `yaml:"collectionFormat,omitempty" json:"collectionFormat,omitempty"`
+
+	Enum []interface{} `yaml:"enum,omitempty" json:"enum,omitempty"`
+
 	Examples interface{} `yaml:"x-example" json:"x-example"`
+
+	node *yaml.Node // the source of the parameter for error messages
+}
+
+func (p *Parameter) UnmarshalYAML(value *yaml.Node) error {
+	type plain Parameter
+	p.node = value
+	return value.Decode((*plain)(p))
 }
 
 type Response struct {
 	Description string                 `yaml:"description" json:"description"`
 	Schema      *Schema                `yaml:"schema" json:"schema"`
+	Headers     map[string]*Header     `yaml:"headers" json:"headers,omitempty"`
 	Examples    map[string]interface{} `yaml:"examples" json:"examples"`
 }
 
-type Security struct {
-	Roles []string `yaml:"roles" json:"roles"`
+// Header is a header of a response. Required is only set by OpenAPI 3.0
+// documents.
+type Header struct {
+	Description      string        `yaml:"description" json:"description,omitempty"`
+	Required         bool          `yaml:"required" json:"required,omitempty"`
+	Type             string        `yaml:"type" json:"type"`
+	Format           string        `yaml:"format,omitempty" json:"format,omitempty"`
+	Items            *Schema       `yaml:"items,omitempty" json:"items,omitempty"`
+	CollectionFormat string        `yaml:"collectionFormat,omitempty" json:"collectionFormat,omitempty"`
+	Enum             []interface{} `yaml:"enum,omitempty" json:"enum,omitempty"`
+}
+
+// SecurityRequirement maps the names of security schemes to the required
+// scopes. A request has to satisfy all schemes of one of the requirements of
+// an operation.
+type SecurityRequirement map[string][]string
+
+type SecurityScheme struct {
+	Type             string            `yaml:"type" json:"type"`
+	Description      string            `yaml:"description" json:"description,omitempty"`
+	Name             string            `yaml:"name" json:"name,omitempty"`
+	In               string            `yaml:"in" json:"in,omitempty"`
+	Flow             string            `yaml:"flow" json:"flow,omitempty"`
+	AuthorizationURL string            `yaml:"authorizationUrl" json:"authorizationUrl,omitempty"`
+	TokenURL         string            `yaml:"tokenUrl" json:"tokenUrl,omitempty"`
+	OpenIDConnectURL string            `yaml:"x-openIdConnectUrl" json:"x-openIdConnectUrl,omitempty"`
+	Scheme           string            `yaml:"x-scheme" json:"x-scheme,omitempty"`
+	Scopes           map[string]string `yaml:"scopes" json:"scopes,omitempty"`
 }
 
 type Schema struct {
@@ -63,11 +145,54 @@ type Schema struct {
 	Title                string             `yaml:"title,omitempty" json:"title,omitempty"`
 	Description          string             `yaml:"description" json:"description,omitempty"`
 	Default              interface{}        `yaml:"default,omitempty" json:"default,omitempty"`
+	Minimum              interface{}        `yaml:"minimum,omitempty" json:"minimum,omitempty"`
 	Maximum              interface{}        `yaml:"maximum,omitempty" json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `yaml:"exclusiveMinimum,omitempty" json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `yaml:"exclusiveMaximum,omitempty" json:"exclusiveMaximum,omitempty"`
+	MultipleOf           interface{}        `yaml:"multipleOf,omitempty" json:"multipleOf,omitempty"`
+	MinLength            *int               `yaml:"minLength,omitempty" json:"minLength,omitempty"`
+	MaxLength            *int               `yaml:"maxLength,omitempty" json:"maxLength,omitempty"`
+	Pattern              string             `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	MinItems             *int               `yaml:"minItems,omitempty" json:"minItems,omitempty"`
+	MaxItems             *int               `yaml:"maxItems,omitempty" json:"maxItems,omitempty"`
+	UniqueItems          bool               `yaml:"uniqueItems,omitempty" json:"uniqueItems,omitempty"`
+	MinProperties        *int               `yaml:"minProperties,omitempty" json:"minProperties,omitempty"`
+	MaxProperties        *int               `yaml:"maxProperties,omitempty" json:"maxProperties,omitempty"`
 	Items                *Schema            `yaml:"items,omitempty" json:"items,omitempty"`
 	Type                 string             `yaml:"type" json:"type,omitempty"`
 	AdditionalProperties *Schema            `yaml:"additionalProperties,omitempty" json:"additionalProperties,omitempty"`
-	Enum                 []string           `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Enum                 []interface{}      `yaml:"enum,omitempty" json:"enum,omitempty"`
 	Properties           map[string]*Schema `yaml:"properties" json:"properties,omitempty"`
 	Required             []string           `yaml:"required" json:"required,omitempty"`
+	AllOf                []*Schema          `yaml:"allOf,omitempty" json:"allOf,omitempty"`
+	OneOf                []*Schema          `yaml:"oneOf,omitempty" json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `yaml:"anyOf,omitempty" json:"anyOf,omitempty"`
+	Discriminator        *Discriminator     `yaml:"discriminator,omitempty" json:"-"`
+	DiscriminatorValue   string             `yaml:"x-discriminator-value,omitempty" json:"-"`
+
+	resolved *Schema    // the definition a reference points to
+	node     *yaml.Node // the source of the schema for error messages
+}
+
+func (s *Schema) UnmarshalYAML(value *yaml.Node) error {
+	type plain Schema
+	s.node = value
+	return value.Decode((*plain)(s))
+}
+
+// Discriminator selects the schema of a polymorphic value. Swagger 2.0 only
+// names the property, OpenAPI 3.0 can also map values to schemas.
+type Discriminator struct {
+	PropertyName string            `yaml:"propertyName"`
+	Mapping      map[string]string `yaml:"mapping"`
+}
+
+func (d *Discriminator) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		d.PropertyName = value.Value
+		return nil
+	}
+
+	type plain Discriminator
+	return value.Decode((*plain)(d))
 }
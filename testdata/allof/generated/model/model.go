@@ -0,0 +1,85 @@
+package model
+
+type Admin struct {
+	User
+	Manager     *User    `json:"manager,omitempty" xml:"manager,omitempty"`
+	Permissions []string `json:"permissions,omitempty" xml:"permissions,omitempty"`
+}
+
+// Validate returns an error if m does not match the Admin schema.
+func (m *Admin) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Admin) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	m.User.validate(v, path)
+	if m.Manager != nil {
+		m.Manager.validate(v, field(path, "manager"))
+	}
+}
+
+type Base struct {
+	ID string `json:"id" xml:"id"`
+}
+
+// Validate returns an error if m does not match the Base schema.
+func (m *Base) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Base) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type User struct {
+	Base
+	Address *UserAddress `json:"address,omitempty" xml:"address,omitempty"`
+	Name    string       `json:"name" xml:"name"`
+}
+
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	m.Base.validate(v, path)
+	if m.Address != nil {
+		m.Address.validate(v, field(path, "address"))
+	}
+}
+
+type UserAddress struct {
+	Street *string `json:"street,omitempty" xml:"street,omitempty"`
+}
+
+// Validate returns an error if m does not match the UserAddress schema.
+func (m *UserAddress) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *UserAddress) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/cugu/swagger-go-chi/testdata/content/generated/model"
+)
+
+type Service interface {
+	SendFeedback(context.Context, []string) error
+	ListNotes(context.Context) ([]*model.Note, error)
+	CreateNote(context.Context, *model.Note) (*model.Note, error)
+	SetNoteTags(context.Context, string, *model.Tags) error
+	GetNoteText(context.Context, string) (string, error)
+}
+
+// securitySchemes are the securityDefinitions of the spec.
+var securitySchemes = map[string]SecurityScheme{}
+
+// NewServer returns the router of the API. Requests are authenticated by the
+// authenticate middleware, if it is set, and the security requirements of
+// the operations are checked by the authorizer. Public operations, which
+// have an empty list of security requirements, skip both. The errors of the
+// service are mapped to problems by the errorMapper, if it is set, and by
+// MapErrors.
+func NewServer(service Service, authenticate func(http.Handler) http.Handler, authorizer Authorizer, errorMapper ErrorMapper, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	if authenticate == nil {
+		authenticate = NilMiddleware()
+	}
+
+	s := &server{service, errorMapper}
+
+	r.With(authenticate).Post("/feedback", s.sendFeedbackHandler)
+	r.With(authenticate).Get("/notes", s.listNotesHandler)
+	r.With(authenticate).Post("/notes", s.createNoteHandler)
+	r.With(authenticate).Put("/notes/{id}/tags", s.setNoteTagsHandler)
+	r.With(authenticate).Get("/notes/{id}/text", s.getNoteTextHandler)
+	return r
+}
+
+// ValidateResponses returns a middleware that checks the responses of the
+// operations against the spec. Violations are logged, if fail is set the
+// response is replaced by a problem with the status 500.
+func ValidateResponses(fail bool) func(http.Handler) http.Handler {
+	return validateResponses(operationSpecs, fail)
+}
+
+// operationSpecs are the declared responses of the operations by method and
+// route pattern.
+var operationSpecs = map[string]*operationSpec{
+	"POST /feedback": {
+		produces: []string{"application/json", "application/xml", "application/yaml"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+	"GET /notes": {
+		produces: []string{"application/json", "application/xml", "application/yaml"},
+		responses: map[string]responseSpec{
+			"200": {body: func() interface{} { return new([]*model.Note) }},
+		},
+	},
+	"POST /notes": {
+		produces: []string{"application/json", "application/xml", "application/yaml"},
+		responses: map[string]responseSpec{
+			"200": {body: func() interface{} { return new(*model.Note) }},
+		},
+	},
+	"PUT /notes/{id}/tags": {
+		produces: []string{"application/json", "application/xml", "application/yaml"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+	"GET /notes/{id}/text": {
+		produces: []string{"text/plain"},
+		responses: map[string]responseSpec{
+			"200": {body: func() interface{} { return new(string) }},
+		},
+	},
+}
+
+type server struct {
+	service     Service
+	errorMapper ErrorMapper
+}
+
+// serviceError maps an error of the service to a problem.
+func (s *server) serviceError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s.errorMapper != nil {
+		if p := s.errorMapper(err); p != nil {
+			return p
+		}
+	}
+	if p := MapErrors(err); p != nil {
+		return p
+	}
+	return err
+}
+
+// MapErrors maps the sentinel errors and error types of the model package
+// to problems, other errors are mapped to nil.
+func MapErrors(err error) *Problem {
+	var validationErrors model.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+		return p.WithExtension("errors", validationErrors.Messages())
+	}
+
+	var status int
+	switch {
+	case errors.Is(err, model.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, model.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, model.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, model.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, model.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	default:
+		return nil
+	}
+
+	p := NewProblem(status, "", err.Error()).WithCause(err)
+	var coded *model.CodedError
+	if errors.As(err, &coded) {
+		p.Code = coded.Code
+	}
+	return p
+}
+
+func (s *server) sendFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+	if _, err := requestMediaType(r, []string{"multipart/form-data", "application/x-www-form-urlencoded"}); err != nil {
+		bodyError(w, err)
+		return
+	}
+	if err := r.ParseMultipartForm(formMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		bodyError(w, err)
+		return
+	}
+	messageP := r.PostForm["message"]
+
+	response(w, nil, nil, s.serviceError(s.service.SendFeedback(r.Context(), messageP)))
+}
+
+func (s *server) listNotesHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json", "application/xml", "application/yaml"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	result, err := s.service.ListNotes(r.Context())
+	response(w, enc, result, s.serviceError(err))
+}
+
+func (s *server) createNoteHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json", "application/xml", "application/yaml"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+
+	var noteP *model.Note
+	if err := decodeBody(r, []string{"application/json", "application/xml"}, &noteP); err != nil {
+		bodyError(w, err)
+		return
+	}
+
+	if validateBody(w, noteP) {
+		return
+	}
+
+	result, err := s.service.CreateNote(r.Context(), noteP)
+	response(w, enc, result, s.serviceError(err))
+}
+
+func (s *server) setNoteTagsHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+	idP := chi.URLParam(r, "id")
+
+	var tagsP *model.Tags
+	if err := decodeBody(r, []string{"application/x-www-form-urlencoded"}, &tagsP); err != nil {
+		bodyError(w, err)
+		return
+	}
+
+	if validateBody(w, tagsP) {
+		return
+	}
+
+	response(w, nil, nil, s.serviceError(s.service.SetNoteTags(r.Context(), idP, tagsP)))
+}
+
+func (s *server) getNoteTextHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"text/plain"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	idP := chi.URLParam(r, "id")
+
+	result, err := s.service.GetNoteText(r.Context(), idP)
+	response(w, enc, result, s.serviceError(err))
+}
@@ -0,0 +1,63 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "SendFeedback",
+		Args: Args{Method: "Post", URL: "/feedback"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "ListNotes",
+		Args: Args{Method: "Get", URL: "/notes"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "CreateNote",
+		Args: Args{Method: "Post", URL: "/notes"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "SetNoteTags",
+		Args: Args{Method: "Put", URL: "/notes/%7Bid%7D/tags"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "GetNoteText",
+		Args: Args{Method: "Get", URL: "/notes/%7Bid%7D/text"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+}
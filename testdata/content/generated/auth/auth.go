@@ -0,0 +1,566 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/cugu/swagger-go-chi/testdata/content/generated/api"
+)
+
+type ContextKey string
+
+const (
+	stateSession              = "state"
+	userSession               = "user"
+	UserContext    ContextKey = "user"
+	SessionContext ContextKey = "session"
+
+	stateMaxAge = 10 * time.Minute
+
+	// refreshBefore is the time before the expiry of the access token at which
+	// it is refreshed.
+	refreshBefore = time.Minute
+)
+
+// errLoginExpired is returned if the ID token of a session expired and could
+// not be renewed.
+var errLoginExpired = errors.New("login expired")
+
+// Required authenticates requests with a bearer token or with the session of
+// the session cookie. Requests without user are redirected to the login of
+// the identity provider.
+func Required(sessions *Sessions, oidcURL string, oauth2Config oauth2.Config, verifier *oidc.IDTokenVerifier) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+
+			if authHeader != "" {
+				bearerAuth(oidcURL, authHeader, verifier)(next).ServeHTTP(w, r)
+
+				return
+			}
+			sessionAuth(sessions, oauth2Config, verifier)(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+type Claims struct {
+	Acr               string   `json:"acr"`
+	AllowedOrigins    []string `json:"allowed-origins"`
+	AuthTime          int      `json:"auth_time"`
+	Azp               string   `json:"azp"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	Exp               int      `json:"exp"`
+	FamilyName        string   `json:"family_name"`
+	GivenName         string   `json:"given_name"`
+	Groups            []string `json:"groups"`
+	Iat               int      `json:"iat"`
+	Iss               string   `json:"iss"`
+	Jti               string   `json:"jti"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+	RealmAccess       struct {
+		Roles []string `json:"roles"`
+	} `json:"realm_access"`
+	ResourceAccess struct {
+		App struct {
+			Roles []string `json:"roles"`
+		} `json:"app"`
+	} `json:"resource_access"`
+	Scope        string `json:"scope"`
+	SessionState string `json:"session_state"`
+	Sub          string `json:"sub"`
+	Typ          string `json:"typ"`
+
+	raw map[string]interface{} // all claims of the token
+}
+
+func (c *Claims) UnmarshalJSON(b []byte) error {
+	type plain Claims
+	if err := json.Unmarshal(b, (*plain)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &c.raw)
+}
+
+// MarshalJSON encodes the claims including the claims of the token that
+// have no field.
+func (c *Claims) MarshalJSON() ([]byte, error) {
+	type plain Claims
+	b, err := json.Marshal((*plain)(c))
+	if err != nil || len(c.raw) == 0 {
+		return b, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeClaims(c.raw, fields))
+}
+
+// mergeClaims returns the claims of a overwritten by the claims of b, nested
+// objects are merged.
+func mergeClaims(a, b map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for key, value := range a {
+		merged[key] = value
+	}
+	for key, value := range b {
+		nestedA, okA := merged[key].(map[string]interface{})
+		nestedB, okB := value.(map[string]interface{})
+		if okA && okB {
+			merged[key] = mergeClaims(nestedA, nestedB)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// Claim returns the claim at a dot separated path, like
+// resource_access.app.roles, or nil if it does not exist.
+func (c *Claims) Claim(path string) interface{} {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil
+	}
+	var claim interface{}
+	if err := json.Unmarshal(b, &claim); err != nil {
+		return nil
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := claim.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		claim = object[key]
+	}
+	return claim
+}
+
+// Roles returns the roles in the claims at the paths, which are strings or
+// lists of strings.
+func (c *Claims) Roles(paths ...string) []string {
+	var roles []string
+	for _, path := range paths {
+		switch claim := c.Claim(path).(type) {
+		case string:
+			roles = append(roles, claim)
+		case []interface{}:
+			for _, item := range claim {
+				if role, ok := item.(string); ok {
+					roles = append(roles, role)
+				}
+			}
+		}
+	}
+	return roles
+}
+
+func bearerAuth(oidcURL string, authHeader string, verifier *oidc.IDTokenVerifier) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(authHeader, "Bearer ") {
+				api.JSONErrorStatus(w, http.StatusUnauthorized, errors.New("no bearer token"))
+				return
+			}
+
+			authToken, err := verifier.Verify(r.Context(), authHeader[7:])
+			if err != nil {
+				api.JSONError(w, fmt.Errorf("could not verify bearer token: %v", err))
+
+				return
+			}
+
+			claims := &Claims{}
+			if err := authToken.Claims(claims); err != nil {
+				api.JSONError(w, fmt.Errorf("failed to parse claims: %v", err))
+
+				return
+			}
+
+			if claims.Iss != oidcURL {
+				api.JSONError(w, fmt.Errorf("wrong issuer"))
+
+				return
+			}
+
+			// set user context
+			r = r.WithContext(context.WithValue(r.Context(), UserContext, claims))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func sessionAuth(sessions *Sessions, oauth2Config oauth2.Config, verifier *oidc.IDTokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := sessions.Load(r)
+			if err != nil {
+				// missing, forged, revoked and expired sessions need a new login
+				if isSessionError(err) {
+					redirectToLogin(w, r, sessions.Cookies, oauth2Config)
+				} else {
+					api.WriteError(w, fmt.Errorf("could not load session: %w", err))
+				}
+
+				return
+			}
+
+			// sessions that cannot be renewed need a new login
+			session, err = refresh(r.Context(), sessions, session, oauth2Config, verifier)
+			if err != nil {
+				if !errors.Is(err, errLoginExpired) && !isSessionError(err) {
+					api.WriteError(w, fmt.Errorf("could not refresh session: %w", err))
+
+					return
+				}
+				if err := sessions.End(w, r); err != nil {
+					api.WriteError(w, fmt.Errorf("could not end session: %w", err))
+
+					return
+				}
+				redirectToLogin(w, r, sessions.Cookies, oauth2Config)
+
+				return
+			}
+
+			// set user and session context
+			r = r.WithContext(context.WithValue(r.Context(), UserContext, session.Claims))
+			r = r.WithContext(context.WithValue(r.Context(), SessionContext, session))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// refresh renews the tokens of the session with its refresh token, if the
+// access token expires soon or the ID token expired. Refreshes start from the
+// stored session and are serialized, as the identity provider may rotate the
+// refresh token. It returns errLoginExpired if the ID token expired and
+// could not be renewed or if the refresh token was rejected. Other errors of
+// the identity provider are logged and the session is renewed by a later
+// request.
+func refresh(ctx context.Context, sessions *Sessions, session *Session, oauth2Config oauth2.Config, verifier *oidc.IDTokenVerifier) (*Session, error) {
+	now := sessions.now()
+	if !needsRefresh(session, now) {
+		return session, nil
+	}
+
+	return sessions.update(ctx, session.ID, func(session *Session) (bool, error) {
+		// another request may have refreshed the session meanwhile
+		if !needsRefresh(session, now) {
+			return false, nil
+		}
+		if session.Token == nil || session.Token.RefreshToken == "" {
+			if loginExpired(session, now) {
+				return false, errLoginExpired
+			}
+			return false, nil
+		}
+
+		// a token without access token is always refreshed
+		token, err := oauth2Config.TokenSource(ctx, &oauth2.Token{RefreshToken: session.Token.RefreshToken}).Token()
+		if err != nil {
+			var retrieveError *oauth2.RetrieveError
+			if (errors.As(err, &retrieveError) && retrieveError.ErrorCode == "invalid_grant") || loginExpired(session, now) {
+				return false, fmt.Errorf("%w: %v", errLoginExpired, err)
+			}
+			log.Printf("token refresh failed: %v", err)
+			return false, nil
+		}
+		session.Token = token
+
+		if rawIDToken, ok := token.Extra("id_token").(string); ok {
+			claims, err := verifyClaims(ctx, verifier, rawIDToken)
+			if err != nil {
+				// the new tokens are kept, as the refresh token may be rotated
+				log.Printf("token verification failed: %v", err)
+			} else {
+				session.Claims, session.IDToken = claims, rawIDToken
+			}
+		}
+		if loginExpired(session, now) {
+			return false, errLoginExpired
+		}
+		return true, nil
+	})
+}
+
+// needsRefresh returns true if the access token of the session expires soon
+// or its ID token expired.
+func needsRefresh(session *Session, now time.Time) bool {
+	tokenExpiring := session.Token != nil && !session.Token.Expiry.IsZero() && !now.Add(refreshBefore).Before(session.Token.Expiry)
+	return tokenExpiring || loginExpired(session, now)
+}
+
+// loginExpired returns true if the ID token of the session expired.
+func loginExpired(session *Session, now time.Time) bool {
+	return session.Claims != nil && session.Claims.Exp != 0 && !now.Before(time.Unix(int64(session.Claims.Exp), 0))
+}
+
+// verifyClaims verifies an ID token and returns its claims.
+func verifyClaims(ctx context.Context, verifier *oidc.IDTokenVerifier, rawIDToken string) (*Claims, error) {
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	claims := &Claims{}
+	if err := idToken.Claims(claims); err != nil {
+		return nil, fmt.Errorf("claim extraction failed: %w", err)
+	}
+	return claims, nil
+}
+
+func isSessionError(err error) bool {
+	for _, target := range []error{http.ErrNoCookie, ErrInvalidCookie, ErrExpiredCookie, ErrSessionNotFound, ErrSessionExpired} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func redirectToLogin(w http.ResponseWriter, r *http.Request, cookies *Cookies, oauth2Config oauth2.Config) {
+	state, err := state()
+	if err != nil {
+		api.JSONError(w, fmt.Errorf("generating state failed"))
+
+		return
+	}
+
+	if err := cookies.Set(w, stateSession, state, stateMaxAge); err != nil {
+		api.JSONError(w, fmt.Errorf("could not set state: %v", err))
+
+		return
+	}
+
+	http.Redirect(w, r, oauth2Config.AuthCodeURL(state), http.StatusFound)
+}
+
+// Callback handles the redirect from the identity provider after the login
+// and starts a session with the claims of the ID token.
+func Callback(sessions *Sessions, oauth2Config oauth2.Config, verifier *oidc.IDTokenVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var state string
+		if err := sessions.Cookies.Get(r, stateSession, &state); err != nil {
+			api.JSONError(w, fmt.Errorf("state missing: %v", err))
+
+			return
+		}
+		sessions.Cookies.Delete(w, stateSession)
+
+		if state == "" || state != r.URL.Query().Get("state") {
+			api.JSONError(w, fmt.Errorf("state mismatch"))
+
+			return
+		}
+
+		oauth2Token, err := oauth2Config.Exchange(r.Context(), r.URL.Query().Get("code"))
+		if err != nil {
+			api.JSONError(w, fmt.Errorf("oauth2 exchange failed"))
+
+			return
+		}
+
+		// Extract the ID Token from OAuth2 token.
+		rawIDToken, ok := oauth2Token.Extra("id_token").(string)
+		if !ok {
+			api.JSONError(w, fmt.Errorf("missing id token"))
+
+			return
+		}
+
+		// Parse and verify ID Token payload.
+		idToken, err := verifier.Verify(r.Context(), rawIDToken)
+		if err != nil {
+			api.JSONError(w, fmt.Errorf("token verification failed"))
+
+			return
+		}
+
+		// Extract custom claims
+		claims := &Claims{}
+		if err := idToken.Claims(claims); err != nil {
+			api.JSONError(w, fmt.Errorf("claim extraction failed"))
+
+			return
+		}
+
+		// replace the previous session of the browser
+		if err := sessions.End(w, r); err != nil {
+			api.WriteError(w, fmt.Errorf("could not end session: %w", err))
+
+			return
+		}
+		if _, err := sessions.Create(w, r, claims, oauth2Token); err != nil {
+			api.WriteError(w, fmt.Errorf("could not create session: %w", err))
+
+			return
+		}
+
+		http.Redirect(w, r, "/", http.StatusFound)
+	}
+}
+
+// Logout ends the session and redirects to the end_session_endpoint of the
+// identity provider to end the login there as well. Without endpoint, the
+// user is redirected to the postLogoutURL or to /.
+func Logout(sessions *Sessions, oauth2Config oauth2.Config, endSessionURL, postLogoutURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var idToken string
+		if session, err := sessions.Load(r); err == nil {
+			idToken = session.IDToken
+		}
+
+		if err := sessions.End(w, r); err != nil {
+			api.WriteError(w, fmt.Errorf("could not end session: %w", err))
+
+			return
+		}
+
+		if endSessionURL == "" {
+			// the handler is shared by all requests, so the default is not
+			// stored in postLogoutURL
+			redirectURL := postLogoutURL
+			if redirectURL == "" {
+				redirectURL = "/"
+			}
+			http.Redirect(w, r, redirectURL, http.StatusFound)
+
+			return
+		}
+
+		u, err := url.Parse(endSessionURL)
+		if err != nil {
+			api.WriteError(w, fmt.Errorf("invalid end session endpoint: %w", err))
+
+			return
+		}
+		query := u.Query()
+		query.Set("client_id", oauth2Config.ClientID)
+		if idToken != "" {
+			query.Set("id_token_hint", idToken)
+		}
+		if postLogoutURL != "" {
+			query.Set("post_logout_redirect_uri", postLogoutURL)
+		}
+		u.RawQuery = query.Encode()
+
+		http.Redirect(w, r, u.String(), http.StatusFound)
+	}
+}
+
+// EndSessionEndpoint returns the end_session_endpoint of the provider or an
+// empty string if the provider does not support RP-initiated logout.
+func EndSessionEndpoint(provider *oidc.Provider) string {
+	var metadata struct {
+		EndSessionEndpoint string `json:"end_session_endpoint"`
+	}
+	if err := provider.Claims(&metadata); err != nil {
+		return ""
+	}
+	return metadata.EndSessionEndpoint
+}
+
+func Group(allowedGroups ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(UserContext).(*Claims)
+			if ok {
+				if !(contains(user.Groups, allowedGroups)) { // || contains([]string{"service"}, user.ResourceAccess.App.Roles)) {
+					api.JSONErrorStatus(w, http.StatusUnauthorized, errors.New("group not allowed"))
+					return
+				}
+			} else {
+				api.JSONErrorStatus(w, http.StatusUnauthorized, errors.New("no user in context"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DefaultRoleClaims are the paths of the claims that contain the realm roles
+// and groups of a user.
+var DefaultRoleClaims = []string{"realm_access.roles", "groups"}
+
+// Authorize returns the authorizer of the security requirements for
+// api.NewServer. It checks oauth2, openIdConnect and bearer schemes with the
+// user of the session or bearer token, the user needs all scopes of the
+// scheme as roles. The roles are read from the claims at the paths, like
+// DefaultRoleClaims or the client roles resource_access.<client id>.roles.
+// The legacy roles scheme only needs one of its roles. Other schemes, like
+// apiKey and basic, are rejected.
+func Authorize(claimPaths ...string) api.Authorizer {
+	return func(r *http.Request, scheme api.SecurityScheme, scopes []string) error {
+		legacyRoles := scheme.Type == "" && scheme.Name == "roles"
+		bearer := scheme.Type == "apiKey" && scheme.Scheme == "bearer"
+		if scheme.Type != "oauth2" && scheme.Type != "openIdConnect" && !bearer && !legacyRoles {
+			return api.Unauthorized("unsupported_scheme", fmt.Sprintf("the security scheme %s cannot be checked", scheme.Name))
+		}
+
+		user, ok := r.Context().Value(UserContext).(*Claims)
+		if !ok {
+			return api.Unauthorized("no_user", "no user in context")
+		}
+		if len(scopes) == 0 {
+			return nil
+		}
+
+		roles := user.Roles(claimPaths...)
+		if legacyRoles {
+			if !contains(roles, scopes) {
+				return api.Forbidden("missing_role", "the user has none of the required roles").WithExtension("roles", scopes)
+			}
+			return nil
+		}
+
+		var missing []string
+		for _, scope := range scopes {
+			if !contains(roles, []string{scope}) {
+				missing = append(missing, scope)
+			}
+		}
+		if len(missing) > 0 {
+			return api.Forbidden("missing_role", "the user misses required roles").WithExtension("roles", missing)
+		}
+		return nil
+	}
+}
+
+func state() (string, error) {
+	rnd := make([]byte, 32)
+	if _, err := rand.Read(rnd); err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(rnd), nil
+}
+
+func contains(userGroups, allowedGroups []string) bool {
+	for _, allowedGroup := range allowedGroups {
+		for _, userGroup := range userGroups {
+			if userGroup == allowedGroup {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/coreos/go-oidc"
+	"github.com/go-chi/chi"
+	"golang.org/x/oauth2"
+
+	"github.com/cugu/swagger-go-chi/testdata/content/generated/api"
+	"github.com/cugu/swagger-go-chi/testdata/content/generated/auth"
+)
+
+type CLI struct {
+	Debug bool `env:"DEBUG" default:"false"`
+	Dev   bool `env:"DEV" default:"false"`
+
+	OIDCURL           string        `name:"oidc-url"            env:"OIDC_URL"            required:""`
+	OIDCIssuer        string        `name:"oidc-issuer"         env:"OIDC_ISSUER"         required:""`
+	OIDCRedirectURL   string        `name:"oidc-redirect-url"   env:"OIDC_REDIRECT_URL"   required:""`
+	OIDCClientID      string        `name:"oidc-client-id"      env:"OIDC_CLIENT_ID"      required:""`
+	OIDCClientSecret  string        `name:"oidc-client-secret"  env:"OIDC_CLIENT_SECRET"  required:""`
+	OIDCPostLogoutURL string        `name:"oidc-post-logout-url" env:"OIDC_POST_LOGOUT_URL" help:"URL the identity provider redirects to after the logout."`
+	OIDCScopes        []string      `name:"oidc-scopes"         env:"OIDC_SCOPES"                                      help:"Additional scopes, ['oidc', 'profile', 'email'] are always added." placeholder:"customscopes"`
+	OIDCClaimUsername string        `name:"oidc-claim-username" env:"OIDC_CLAIM_USERNAME" default:"preferred_username" help:"username field in the OIDC claim"`
+	OIDCClaimEmail    string        `name:"oidc-claim-email"    env:"OIDC_CLAIM_EMAIL"    default:"email"              help:"email field in the OIDC claim"`
+	OIDCClaimName     string        `name:"oidc-claim-name"     env:"OIDC_CLAIM_NAME"     default:"name"               help:"name field in the OIDC claim"`
+	AuthGroups        []string      `env:"AUTH_GROUPS"`
+	AuthRoleClaims    []string      `name:"auth-role-claims"    env:"AUTH_ROLE_CLAIMS"    default:"realm_access.roles,groups" help:"Claims with the roles of the users, the client roles of the OIDC client are always added."`
+	AuthDisabled      bool          `env:"AUTH_DISABLED"`
+	SessionDir        string        `name:"session-dir"         env:"SESSION_DIR"                                      help:"Directory to store the sessions in, sessions are kept in memory if empty." type:"path"`
+	SessionIdle       time.Duration `name:"session-idle"        env:"SESSION_IDLE"        default:"1h"                 help:"End sessions without requests after this duration."`
+	SessionLifetime   time.Duration `name:"session-lifetime"    env:"SESSION_LIFETIME"    default:"24h"                help:"End sessions after this duration since the login."`
+	SessionKeys       []string      `name:"session-keys"        env:"SESSION_KEYS"                                     help:"Base64 encoded keys of 16, 24 or 32 bytes to encrypt the session cookies. New cookies use the first key, so keys can be rotated by adding a key in front." placeholder:"key"`
+}
+
+func NewServer(config CLI, s api.Service, fsys fs.FS) (chi.Router, error) {
+	if config.Debug {
+		log.SetFlags(log.LstdFlags | log.Lshortfile)
+		log.SetOutput(os.Stdout)
+	} else {
+		log.SetOutput(io.Discard)
+	}
+	api.Debug = config.Debug
+
+	server := chi.NewRouter()
+
+	var middlewares []func(next http.Handler) http.Handler
+	authenticate := api.NilMiddleware()
+	authorizer := api.Authorizer(api.IgnoreSecurity)
+	if !config.AuthDisabled {
+		// OIDC connection
+		provider, err := oidc.NewProvider(context.Background(), config.OIDCIssuer)
+		if err != nil {
+			return nil, err
+		}
+		oauth2Config := oauth2.Config{
+			ClientID:     config.OIDCClientID,
+			ClientSecret: config.OIDCClientSecret,
+			RedirectURL:  config.OIDCRedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+		}
+		verifier := provider.Verifier(&oidc.Config{SkipClientIDCheck: true})
+
+		keys, err := auth.DecodeKeys(config.SessionKeys...)
+		if err != nil {
+			return nil, err
+		}
+		cookies, err := auth.NewCookies(keys...)
+		if err != nil {
+			return nil, err
+		}
+		var store auth.SessionStore = auth.NewMemoryStore()
+		if config.SessionDir != "" {
+			if store, err = auth.NewFileStore(config.SessionDir); err != nil {
+				return nil, err
+			}
+		}
+		sessions := auth.NewSessions(store, cookies)
+		sessions.IdleTimeout = config.SessionIdle
+		sessions.AbsoluteTimeout = config.SessionLifetime
+
+		// every request needs a login, except for the public operations
+		required := auth.Required(sessions, config.OIDCURL, oauth2Config, verifier)
+		group := auth.Group(config.AuthGroups...)
+		authenticate = func(next http.Handler) http.Handler {
+			return required(group(next))
+		}
+		server.Get("/callback", auth.Callback(sessions, oauth2Config, verifier))
+		logout := auth.Logout(sessions, oauth2Config, auth.EndSessionEndpoint(provider), config.OIDCPostLogoutURL)
+		server.Get("/logout", logout)
+		server.Post("/logout", logout)
+		authorizer = auth.Authorize(append(config.AuthRoleClaims, "resource_access."+config.OIDCClientID+".roles")...)
+	}
+
+	// check the responses of the API against the spec during development,
+	// static files and the proxy are not part of the spec
+	apiMiddlewares := append([]func(next http.Handler) http.Handler{}, middlewares...)
+	if config.Dev || config.Debug {
+		apiMiddlewares = append(apiMiddlewares, api.ValidateResponses(config.Dev))
+	}
+
+	// server
+	apiEndpoint := api.NewServer(s, authenticate, authorizer, nil, apiMiddlewares...)
+
+	server.Mount("/api", apiEndpoint)
+
+	staticHandler := api.Static(fsys)
+	if config.Dev {
+		log.Println("Use proxy")
+		staticHandler = api.Proxy("http://localhost:8080")
+	}
+	server.Get("/manifest.json", staticHandler)
+	server.With(middlewares...).With(authenticate).NotFound(staticHandler)
+	return server, nil
+}
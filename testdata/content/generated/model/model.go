@@ -0,0 +1,39 @@
+package model
+
+type Note struct {
+	Body  *string `json:"body,omitempty" xml:"body,omitempty"`
+	Title string  `json:"title" xml:"title"`
+}
+
+// Validate returns an error if m does not match the Note schema.
+func (m *Note) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Note) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type Tags struct {
+	Pinned *bool    `json:"pinned,omitempty" xml:"pinned,omitempty"`
+	Tags   []string `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+// Validate returns an error if m does not match the Tags schema.
+func (m *Tags) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Tags) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
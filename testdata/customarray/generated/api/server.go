@@ -2,7 +2,7 @@ package api
 
 import (
 	"context"
-	"io"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi"
@@ -14,36 +14,115 @@ type Service interface {
 	CreateUserBatch(context.Context, *model.UserArray) error
 }
 
-func NewServer(service Service, roleAuth func([]string) func(http.Handler) http.Handler, middlewares ...func(http.Handler) http.Handler) chi.Router {
+// securitySchemes are the securityDefinitions of the spec.
+var securitySchemes = map[string]SecurityScheme{}
+
+// NewServer returns the router of the API. Requests are authenticated by the
+// authenticate middleware, if it is set, and the security requirements of
+// the operations are checked by the authorizer. Public operations, which
+// have an empty list of security requirements, skip both. The errors of the
+// service are mapped to problems by the errorMapper, if it is set, and by
+// MapErrors.
+func NewServer(service Service, authenticate func(http.Handler) http.Handler, authorizer Authorizer, errorMapper ErrorMapper, middlewares ...func(http.Handler) http.Handler) chi.Router {
 	r := chi.NewRouter()
 	r.Use(middlewares...)
 
-	s := &server{service}
+	if authenticate == nil {
+		authenticate = NilMiddleware()
+	}
+
+	s := &server{service, errorMapper}
 
-	r.With(roleAuth([]string{""})).Post("/users", s.createUserBatchHandler)
+	r.With(authenticate).Post("/users", s.createUserBatchHandler)
 	return r
 }
 
+// ValidateResponses returns a middleware that checks the responses of the
+// operations against the spec. Violations are logged, if fail is set the
+// response is replaced by a problem with the status 500.
+func ValidateResponses(fail bool) func(http.Handler) http.Handler {
+	return validateResponses(operationSpecs, fail)
+}
+
+// operationSpecs are the declared responses of the operations by method and
+// route pattern.
+var operationSpecs = map[string]*operationSpec{
+	"POST /users": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+}
+
 type server struct {
-	service Service
+	service     Service
+	errorMapper ErrorMapper
 }
 
-func (s *server) createUserBatchHandler(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		JSONError(w, err)
-		return
+// serviceError maps an error of the service to a problem.
+func (s *server) serviceError(err error) error {
+	if err == nil {
+		return nil
 	}
+	if s.errorMapper != nil {
+		if p := s.errorMapper(err); p != nil {
+			return p
+		}
+	}
+	if p := MapErrors(err); p != nil {
+		return p
+	}
+	return err
+}
 
-	if validateSchema(body, model.UserArraySchema, w) {
-		return
+// MapErrors maps the sentinel errors and error types of the model package
+// to problems, other errors are mapped to nil.
+func MapErrors(err error) *Problem {
+	var validationErrors model.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+		return p.WithExtension("errors", validationErrors.Messages())
+	}
+
+	var status int
+	switch {
+	case errors.Is(err, model.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, model.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, model.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, model.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, model.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	default:
+		return nil
 	}
 
+	p := NewProblem(status, "", err.Error()).WithCause(err)
+	var coded *model.CodedError
+	if errors.As(err, &coded) {
+		p.Code = coded.Code
+	}
+	return p
+}
+
+func (s *server) createUserBatchHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+
 	var usersP *model.UserArray
-	if err := parseBody(body, &usersP); err != nil {
-		JSONError(w, err)
+	if err := decodeBody(r, []string{"application/json"}, &usersP); err != nil {
+		bodyError(w, err)
+		return
+	}
+
+	if usersP != nil && validateBody(w, usersP) {
 		return
 	}
 
-	response(w, nil, s.service.CreateUserBatch(r.Context(), usersP))
+	response(w, nil, nil, s.serviceError(s.service.CreateUserBatch(r.Context(), usersP)))
 }
@@ -1,7 +1,5 @@
 package api
 
-import "time"
-
 type Args struct {
 	Method string
 	URL    string
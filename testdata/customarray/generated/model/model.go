@@ -1,42 +1,36 @@
 package model
 
-import (
-	"time"
-
-	"github.com/xeipuuv/gojsonschema"
-)
-
-var (
-	schemaLoader    = gojsonschema.NewSchemaLoader()
-	UserSchema      = new(gojsonschema.Schema)
-	UserArraySchema = new(gojsonschema.Schema)
-)
-
-func init() {
-	err := schemaLoader.AddSchemas(
-		gojsonschema.NewStringLoader(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"],"$id":"#/definitions/User"}`),
-		gojsonschema.NewStringLoader(`{"items":{"$ref":"#/definitions/User"},"type":"array","$id":"#/definitions/UserArray"}`),
-	)
-	if err != nil {
-		panic(err)
-	}
+type User struct {
+	Name string `json:"name" xml:"name"`
+}
 
-	UserSchema = mustCompile(`#/definitions/User`)
-	UserArraySchema = mustCompile(`#/definitions/UserArray`)
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
 }
 
-type User struct {
-	Name string `json:"name"`
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
 }
 
 type UserArray []*User
 
-func mustCompile(uri string) *gojsonschema.Schema {
-	s, err := schemaLoader.Compile(gojsonschema.NewReferenceLoader(uri))
-	if err != nil {
-		panic(err)
-	}
-	return s
+// Validate returns an error if m does not match the UserArray schema.
+func (m UserArray) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
 }
 
-const ()
+func (m UserArray) validate(v *Validator, path string) {
+	for i0, item0 := range m {
+		if item0 != nil {
+			item0.validate(v, index(path, i0))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// formMemory is the size of form data that is kept in memory, larger files
+// of multipart forms are stored on disk.
+const formMemory = 32 << 20
+
+// File is a binary response body that is streamed to the client. The body is
+// closed after the response is written. Bodies that implement io.Seeker
+// support range and conditional requests.
+type File struct {
+	Body        io.ReadCloser
+	ContentType string    // defaults to application/octet-stream
+	Size        int64     // size of the body in bytes, unknown if not positive
+	Name        string    // filename of the Content-Disposition header, optional
+	ModTime     time.Time // modification time for conditional requests, optional
+}
+
+// fileResponse writes a file returned by a service or its error.
+func fileResponse(w http.ResponseWriter, r *http.Request, f *File, err error) {
+	if err != nil {
+		response(w, nil, nil, err)
+		return
+	}
+	if f == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	serveFile(w, r, http.StatusOK, f)
+}
+
+// serveFile streams a file with the status code. Range requests are only
+// served for 200 responses with seekable bodies.
+func serveFile(w http.ResponseWriter, r *http.Request, status int, f *File) {
+	if f == nil || f.Body == nil {
+		JSONErrorStatus(w, http.StatusInternalServerError, errors.New("no file"))
+		return
+	}
+	defer f.Body.Close()
+
+	contentType := f.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if f.Name != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": f.Name}))
+	}
+
+	if seeker, ok := f.Body.(io.ReadSeeker); ok && status == http.StatusOK {
+		http.ServeContent(w, r, f.Name, f.ModTime, seeker)
+		return
+	}
+
+	if f.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(f.Size, 10))
+	}
+	if !f.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", f.ModTime.UTC().Format(http.TimeFormat))
+	}
+	w.WriteHeader(status)
+	io.Copy(w, f.Body)
+}
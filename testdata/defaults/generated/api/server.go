@@ -0,0 +1,280 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/cugu/swagger-go-chi/testdata/defaults/generated/model"
+)
+
+type Service interface {
+	Notify(context.Context, model.Notification) error
+	ListTasks(context.Context, int, model.ListTasksSort, string, model.Date, []string, int32, string) ([]*model.Task, error)
+	CreateTask(context.Context, *model.Task) (*model.Task, error)
+	ImportTasks(context.Context, []*model.Task) error
+	UploadTasks(context.Context, []string, []*multipart.FileHeader) error
+}
+
+// securitySchemes are the securityDefinitions of the spec.
+var securitySchemes = map[string]SecurityScheme{}
+
+// NewServer returns the router of the API. Requests are authenticated by the
+// authenticate middleware, if it is set, and the security requirements of
+// the operations are checked by the authorizer. Public operations, which
+// have an empty list of security requirements, skip both. The errors of the
+// service are mapped to problems by the errorMapper, if it is set, and by
+// MapErrors.
+func NewServer(service Service, authenticate func(http.Handler) http.Handler, authorizer Authorizer, errorMapper ErrorMapper, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	if authenticate == nil {
+		authenticate = NilMiddleware()
+	}
+
+	s := &server{service, errorMapper}
+
+	r.With(authenticate).Post("/notifications", s.notifyHandler)
+	r.With(authenticate).Get("/tasks", s.listTasksHandler)
+	r.With(authenticate).Post("/tasks", s.createTaskHandler)
+	r.With(authenticate).Post("/tasks/import", s.importTasksHandler)
+	r.With(authenticate).Put("/tasks/upload", s.uploadTasksHandler)
+	return r
+}
+
+// ValidateResponses returns a middleware that checks the responses of the
+// operations against the spec. Violations are logged, if fail is set the
+// response is replaced by a problem with the status 500.
+func ValidateResponses(fail bool) func(http.Handler) http.Handler {
+	return validateResponses(operationSpecs, fail)
+}
+
+// operationSpecs are the declared responses of the operations by method and
+// route pattern.
+var operationSpecs = map[string]*operationSpec{
+	"POST /notifications": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+	"GET /tasks": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"200": {body: func() interface{} { return new([]*model.Task) }},
+		},
+	},
+	"POST /tasks": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"200": {body: func() interface{} { return new(*model.Task) }},
+		},
+	},
+	"POST /tasks/import": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+	"PUT /tasks/upload": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+}
+
+type server struct {
+	service     Service
+	errorMapper ErrorMapper
+}
+
+// serviceError maps an error of the service to a problem.
+func (s *server) serviceError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s.errorMapper != nil {
+		if p := s.errorMapper(err); p != nil {
+			return p
+		}
+	}
+	if p := MapErrors(err); p != nil {
+		return p
+	}
+	return err
+}
+
+// MapErrors maps the sentinel errors and error types of the model package
+// to problems, other errors are mapped to nil.
+func MapErrors(err error) *Problem {
+	var validationErrors model.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+		return p.WithExtension("errors", validationErrors.Messages())
+	}
+
+	var status int
+	switch {
+	case errors.Is(err, model.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, model.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, model.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, model.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, model.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	default:
+		return nil
+	}
+
+	p := NewProblem(status, "", err.Error()).WithCause(err)
+	var coded *model.CodedError
+	if errors.As(err, &coded) {
+		p.Code = coded.Code
+	}
+	return p
+}
+
+func (s *server) notifyHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+
+	var notificationV model.NotificationValue
+	if err := decodeBody(r, []string{"application/json"}, &notificationV); err != nil {
+		bodyError(w, err)
+		return
+	}
+	notificationV.ApplyDefaults()
+	if validateBody(w, &notificationV) {
+		return
+	}
+	notificationP := notificationV.Notification
+
+	response(w, nil, nil, s.serviceError(s.service.Notify(r.Context(), notificationP)))
+}
+
+func (s *server) listTasksHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	limitP, err := parseQueryDefaultValue(r, "limit", "20", parseInt)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	sortP, err := parseQueryDefaultValue(r, "sort", "asc", model.ParseListTasksSort)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	qP, err := parseQueryDefaultValue(r, "q", "*", parseString)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	sinceP, err := parseQueryDefaultValue(r, "since", "2024-01-01", model.ParseDate)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	statesP, err := parseQueryValueArray(r, "states", ",", 1000, parseString)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+	statesP = defaultValues(statesP, []string{"open", "done"}, parseString)
+
+	xPageSizeP, err := parseHeaderDefaultValue(r, "X-Page-Size", "50", parseInt32)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	themeP, err := parseCookieDefaultValue(r, "theme", "light", parseString)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	v := &model.Validator{}
+	v.Maximum("limit", float64(limitP), 100)
+
+	if validationError(w, v.Err()) {
+		return
+	}
+
+	result, err := s.service.ListTasks(r.Context(), limitP, sortP, qP, sinceP, statesP, xPageSizeP, themeP)
+	response(w, enc, result, s.serviceError(err))
+}
+
+func (s *server) createTaskHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+
+	var taskP *model.Task
+	if err := decodeBody(r, []string{"application/json"}, &taskP); err != nil {
+		bodyError(w, err)
+		return
+	}
+	taskP.ApplyDefaults()
+
+	if validateBody(w, taskP) {
+		return
+	}
+
+	result, err := s.service.CreateTask(r.Context(), taskP)
+	response(w, enc, result, s.serviceError(err))
+}
+
+func (s *server) importTasksHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+
+	var tasksP []*model.Task
+	if err := decodeBody(r, []string{"application/json"}, &tasksP); err != nil {
+		bodyError(w, err)
+		return
+	}
+	for _, item0 := range tasksP {
+		item0.ApplyDefaults()
+	}
+
+	response(w, nil, nil, s.serviceError(s.service.ImportTasks(r.Context(), tasksP)))
+}
+
+func (s *server) uploadTasksHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+	if _, err := requestMediaType(r, []string{"multipart/form-data"}); err != nil {
+		bodyError(w, err)
+		return
+	}
+	if err := r.ParseMultipartForm(formMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		bodyError(w, err)
+		return
+	}
+	formatP := r.PostForm["format"]
+	if len(formatP) == 0 {
+		formatP = []string{"csv"}
+	}
+
+	fileP := formFiles(r, "file")
+
+	response(w, nil, nil, s.serviceError(s.service.UploadTasks(r.Context(), formatP, fileP)))
+}
@@ -0,0 +1,63 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "Notify",
+		Args: Args{Method: "Post", URL: "/notifications"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "ListTasks",
+		Args: Args{Method: "Get", URL: "/tasks"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "CreateTask",
+		Args: Args{Method: "Post", URL: "/tasks"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "ImportTasks",
+		Args: Args{Method: "Post", URL: "/tasks/import"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "UploadTasks",
+		Args: Args{Method: "Put", URL: "/tasks/upload"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+}
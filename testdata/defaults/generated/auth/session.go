@@ -0,0 +1,522 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	clock "github.com/cugu/swagger-go-chi/testdata/defaults/generated/time"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExpired  = errors.New("session expired")
+)
+
+// Session is the login of a user, which is referenced by the opaque ID in
+// the session cookie. The tokens of the login are kept to refresh the session
+// and to end the login at the identity provider.
+type Session struct {
+	ID       string        `json:"id"`
+	Subject  string        `json:"sub"`
+	Claims   *Claims       `json:"claims"`
+	Token    *oauth2.Token `json:"token,omitempty"`
+	IDToken  string        `json:"id_token,omitempty"` // the raw ID token
+	Created  time.Time     `json:"created"`
+	LastSeen time.Time     `json:"last_seen"`
+}
+
+// SessionStore stores the sessions on the server, so they can be revoked and
+// are not limited by the size of cookies.
+type SessionStore interface {
+	// Get returns the session with the ID or ErrSessionNotFound.
+	Get(ctx context.Context, id string) (*Session, error)
+	// Save creates or replaces a session.
+	Save(ctx context.Context, session *Session) error
+	// Delete removes a session, removing a missing session is no error.
+	Delete(ctx context.Context, id string) error
+	// List returns the sessions of the user with the subject.
+	List(ctx context.Context, subject string) ([]*Session, error)
+	// DeleteExpired removes the sessions for which expired returns true.
+	DeleteExpired(ctx context.Context, expired func(*Session) bool) error
+}
+
+// purgeInterval is the minimal time between two purges of the expired
+// sessions of a store.
+const purgeInterval = 10 * time.Minute
+
+// Sessions manages the sessions of the users in a store. Sessions end after
+// the IdleTimeout without requests or after the AbsoluteTimeout since the
+// login, a timeout of 0 disables it.
+type Sessions struct {
+	Store           SessionStore
+	Cookies         *Cookies // encrypts the session ID and the login state
+	IdleTimeout     time.Duration
+	AbsoluteTimeout time.Duration
+	Clock           clock.Clock // checks the timeouts, clock.DefaultClock if nil
+
+	mu         sync.Mutex
+	lastPurged time.Time
+	locks      map[string]*sessionLock
+}
+
+// sessionLock serializes the updates of a session.
+type sessionLock struct {
+	sync.Mutex
+	users int
+}
+
+// NewSessions returns sessions in the store with an idle timeout of an hour
+// and an absolute timeout of a day.
+func NewSessions(store SessionStore, cookies *Cookies) *Sessions {
+	return &Sessions{
+		Store:           store,
+		Cookies:         cookies,
+		IdleTimeout:     time.Hour,
+		AbsoluteTimeout: 24 * time.Hour,
+	}
+}
+
+// Create starts a new session for the user with the tokens of the login and
+// sets the session cookie. Expired sessions of other users are purged from
+// time to time, so abandoned sessions do not pile up in the store.
+func (s *Sessions) Create(w http.ResponseWriter, r *http.Request, claims *Claims, token *oauth2.Token) (*Session, error) {
+	// the login does not depend on the cleanup of other sessions
+	if err := s.purgeExpired(r.Context()); err != nil {
+		log.Printf("purging expired sessions failed: %v", err)
+	}
+
+	id, err := sessionID()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	session := &Session{ID: id, Subject: claims.Sub, Claims: claims, Token: token, Created: now, LastSeen: now}
+	if token != nil {
+		session.IDToken, _ = token.Extra("id_token").(string)
+	}
+	if err := s.Store.Save(r.Context(), session); err != nil {
+		return nil, err
+	}
+	// without absolute timeout, the session ID is kept in a session cookie
+	if err := s.Cookies.Set(w, userSession, id, s.AbsoluteTimeout); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Load returns the session of the session cookie and marks it as seen. Ended
+// sessions are removed from the store and ErrSessionExpired is returned.
+// The time a session was last seen is only saved when it moved by a tenth of
+// the IdleTimeout, so not every request writes to the store.
+func (s *Sessions) Load(r *http.Request) (*Session, error) {
+	var id string
+	if err := s.Cookies.Get(r, userSession, &id); err != nil {
+		return nil, err
+	}
+	session, err := s.Store.Get(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if s.expired(session, now) {
+		if err := s.Store.Delete(r.Context(), id); err != nil {
+			return nil, err
+		}
+		return nil, ErrSessionExpired
+	}
+
+	if s.IdleTimeout > 0 && now.Sub(session.LastSeen) >= s.IdleTimeout/10 {
+		return s.update(r.Context(), id, func(session *Session) (bool, error) {
+			session.LastSeen = now
+			return true, nil
+		})
+	}
+	return session, nil
+}
+
+// update applies fn to the stored session with the ID and saves it if fn
+// returns true. Updates of a session are serialized and start from the
+// stored session, so concurrent requests do not overwrite each other's
+// changes, like a rotated refresh token.
+func (s *Sessions) update(ctx context.Context, id string, fn func(*Session) (bool, error)) (*Session, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	session, err := s.Store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	save, err := fn(session)
+	if err != nil {
+		return nil, err
+	}
+	if save {
+		if err := s.Store.Save(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+	return session, nil
+}
+
+// lock locks the session with the ID and returns the function to unlock it.
+func (s *Sessions) lock(id string) func() {
+	s.mu.Lock()
+	if s.locks == nil {
+		s.locks = map[string]*sessionLock{}
+	}
+	l, ok := s.locks[id]
+	if !ok {
+		l = &sessionLock{}
+		s.locks[id] = l
+	}
+	l.users++
+	s.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		s.mu.Lock()
+		if l.users--; l.users == 0 {
+			delete(s.locks, id)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// End revokes the session of the session cookie and removes the cookie.
+func (s *Sessions) End(w http.ResponseWriter, r *http.Request) error {
+	var id string
+	err := s.Cookies.Get(r, userSession, &id)
+	s.Cookies.Delete(w, userSession)
+	if err != nil {
+		// there is no valid session to revoke
+		return nil
+	}
+	return s.Revoke(r.Context(), id)
+}
+
+// List returns the active sessions of the user with the subject, ordered by
+// their creation.
+func (s *Sessions) List(ctx context.Context, subject string) ([]*Session, error) {
+	sessions, err := s.Store.List(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var active []*Session
+	for _, session := range sessions {
+		if !s.expired(session, now) {
+			active = append(active, session)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Created.Before(active[j].Created) })
+	return active, nil
+}
+
+// Revoke ends the session with the ID. It waits for running updates of the
+// session, so they do not save it again.
+func (s *Sessions) Revoke(ctx context.Context, id string) error {
+	unlock := s.lock(id)
+	defer unlock()
+
+	return s.Store.Delete(ctx, id)
+}
+
+// RevokeAll ends all sessions of the user with the subject.
+func (s *Sessions) RevokeAll(ctx context.Context, subject string) error {
+	sessions, err := s.Store.List(ctx, subject)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.Revoke(ctx, session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Purge removes all expired sessions from the store.
+func (s *Sessions) Purge(ctx context.Context) error {
+	now := s.now()
+	return s.Store.DeleteExpired(ctx, func(session *Session) bool {
+		return s.expired(session, now)
+	})
+}
+
+// purgeExpired purges the store if the last purge is longer ago than the
+// purgeInterval.
+func (s *Sessions) purgeExpired(ctx context.Context) error {
+	now := s.now()
+
+	s.mu.Lock()
+	due := now.Sub(s.lastPurged) >= purgeInterval
+	if due {
+		s.lastPurged = now
+	}
+	s.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return s.Purge(ctx)
+}
+
+func (s *Sessions) expired(session *Session, now time.Time) bool {
+	if s.AbsoluteTimeout > 0 && !now.Before(session.Created.Add(s.AbsoluteTimeout)) {
+		return true
+	}
+	return s.IdleTimeout > 0 && !now.Before(session.LastSeen.Add(s.IdleTimeout))
+}
+
+func (s *Sessions) now() time.Time {
+	if s.Clock == nil {
+		return clock.Now()
+	}
+	return s.Clock.Now()
+}
+
+func sessionID() (string, error) {
+	rnd := make([]byte, 32)
+	if _, err := rand.Read(rnd); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(rnd), nil
+}
+
+// MemoryStore keeps the sessions in memory, they are lost on restart.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]Session{}}
+}
+
+func (m *MemoryStore) Get(_ context.Context, id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *MemoryStore) Save(_ context.Context, session *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[session.ID] = *session
+	return nil
+}
+
+func (m *MemoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *MemoryStore) List(_ context.Context, subject string) ([]*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sessions []*Session
+	for _, session := range m.sessions {
+		if session.Subject == subject {
+			session := session
+			sessions = append(sessions, &session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *MemoryStore) DeleteExpired(_ context.Context, expired func(*Session) bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, session := range m.sessions {
+		session := session
+		if expired(&session) {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+// validSessionID matches the IDs created by sessionID, so IDs from cookies
+// cannot reference files outside of the directory of a FileStore.
+var validSessionID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// FileStore keeps the sessions as JSON files in a directory, so they survive
+// restarts.
+type FileStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileStore returns a store in the directory, which is created if it does
+// not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (f *FileStore) path(id string) (string, error) {
+	if !validSessionID.MatchString(id) {
+		return "", ErrSessionNotFound
+	}
+	return filepath.Join(f.dir, id+".json"), nil
+}
+
+func (f *FileStore) Get(_ context.Context, id string) (*Session, error) {
+	path, err := f.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return readSession(path)
+}
+
+func (f *FileStore) Save(_ context.Context, session *Session) error {
+	path, err := f.path(session.ID)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// replace the file at once, so readers never see a partial session
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (f *FileStore) Delete(_ context.Context, id string) error {
+	path, err := f.path(id)
+	if err != nil {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (f *FileStore) List(_ context.Context, subject string) ([]*Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*Session
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		session, err := readSession(filepath.Join(f.dir, entry.Name()))
+		if err != nil {
+			skipSession(entry.Name(), err)
+			continue
+		}
+		if session.Subject == subject {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *FileStore) DeleteExpired(_ context.Context, expired func(*Session) bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(f.dir, entry.Name())
+		session, err := readSession(path)
+		if err != nil {
+			skipSession(entry.Name(), err)
+			continue
+		}
+		if !expired(session) {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipSession logs a session file that cannot be read, so a single broken
+// file does not break listing and purging the other sessions. Files removed
+// in the meantime are skipped silently.
+func skipSession(name string, err error) {
+	if !errors.Is(err, ErrSessionNotFound) {
+		log.Printf("skipping session file %s: %v", name, err)
+	}
+}
+
+func readSession(path string) (*Session, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{}
+	if err := json.Unmarshal(b, session); err != nil {
+		return nil, fmt.Errorf("session %s: %w", filepath.Base(path), err)
+	}
+	return session, nil
+}
@@ -0,0 +1,469 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type ListTasksSort string
+
+const (
+	ListTasksSortAsc  ListTasksSort = "asc"
+	ListTasksSortDesc ListTasksSort = "desc"
+)
+
+// AllListTasksSort returns all valid ListTasksSort values.
+func AllListTasksSort() []ListTasksSort {
+	return []ListTasksSort{ListTasksSortAsc, ListTasksSortDesc}
+}
+
+// Valid returns true if v is one of the ListTasksSort values.
+func (v ListTasksSort) Valid() bool {
+	switch v {
+	case ListTasksSortAsc, ListTasksSortDesc:
+		return true
+	}
+	return false
+}
+
+func (v *ListTasksSort) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !ListTasksSort(value).Valid() {
+		return fmt.Errorf("invalid ListTasksSort %v", value)
+	}
+	*v = ListTasksSort(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the ListTasksSort values.
+func (m ListTasksSort) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m ListTasksSort) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllListTasksSort())
+	}
+}
+
+// ParseListTasksSort parses a ListTasksSort from its string representation.
+func ParseListTasksSort(s string) (ListTasksSort, error) {
+	v := ListTasksSort(s)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid ListTasksSort %q", s)
+	}
+	return v, nil
+}
+
+type Mail struct {
+	NotificationBase
+	Subject *string `json:"subject,omitempty" xml:"subject,omitempty"`
+}
+
+// Validate returns an error if m does not match the Mail schema.
+func (m *Mail) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Mail) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	m.NotificationBase.validate(v, path)
+}
+
+// ApplyDefaults sets the defaults of the Mail schema for absent fields.
+func (m *Mail) ApplyDefaults() {
+	if m == nil {
+		return
+	}
+	if m.Subject == nil {
+		value := "(no subject)"
+		m.Subject = &value
+	}
+}
+
+// Notification is one of the types of the kind mapping.
+type Notification interface {
+	isNotification()
+	validate(v *Validator, path string)
+}
+
+func (*Mail) isNotification() {}
+
+// MarshalJSON writes Mail with the kind "Mail".
+func (m Mail) MarshalJSON() ([]byte, error) {
+	type plain Mail
+	m.Kind = "Mail"
+	return json.Marshal(plain(m))
+}
+
+func (*Push) isNotification() {}
+
+// MarshalJSON writes Push with the kind "Push".
+func (m Push) MarshalJSON() ([]byte, error) {
+	type plain Push
+	m.Kind = "Push"
+	return json.Marshal(plain(m))
+}
+
+// UnmarshalNotification decodes JSON into the type selected by the kind property.
+func UnmarshalNotification(b []byte) (Notification, error) {
+	var discriminator struct {
+		Value string `json:"kind"`
+	}
+	if err := json.Unmarshal(b, &discriminator); err != nil {
+		return nil, err
+	}
+
+	var v Notification
+	switch discriminator.Value {
+	case "Mail":
+		v = &Mail{}
+	case "Push":
+		v = &Push{}
+	default:
+		return nil, fmt.Errorf("unknown kind %q", discriminator.Value)
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// NotificationValue wraps a Notification so it can be decoded from JSON.
+type NotificationValue struct {
+	Notification
+}
+
+func (v *NotificationValue) UnmarshalJSON(b []byte) error {
+	value, err := UnmarshalNotification(b)
+	if err != nil {
+		return err
+	}
+	v.Notification = value
+	return nil
+}
+
+func (v NotificationValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Notification)
+}
+
+// Validate returns an error if the value does not match the Notification schema.
+func (m *NotificationValue) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *NotificationValue) validate(v *Validator, path string) {
+	if m == nil || m.Notification == nil {
+		v.Required(path)
+		return
+	}
+	m.Notification.validate(v, path)
+}
+
+// ApplyDefaults sets the defaults of the type selected by the kind property.
+func (m *NotificationValue) ApplyDefaults() {
+	if m == nil {
+		return
+	}
+	if d, ok := m.Notification.(interface{ ApplyDefaults() }); ok {
+		d.ApplyDefaults()
+	}
+}
+
+type NotificationBase struct {
+	Kind string `json:"kind" xml:"kind"`
+}
+
+// Validate returns an error if m does not match the NotificationBase schema.
+func (m *NotificationBase) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *NotificationBase) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type Person struct {
+	Locale *string `json:"locale,omitempty" xml:"locale,omitempty"`
+	Name   *string `json:"name,omitempty" xml:"name,omitempty"`
+}
+
+// Validate returns an error if m does not match the Person schema.
+func (m *Person) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Person) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+// ApplyDefaults sets the defaults of the Person schema for absent fields.
+func (m *Person) ApplyDefaults() {
+	if m == nil {
+		return
+	}
+	if m.Locale == nil {
+		value := "en"
+		m.Locale = &value
+	}
+}
+
+type Priority int
+
+const (
+	Priority1 Priority = 1
+	Priority2 Priority = 2
+	Priority3 Priority = 3
+)
+
+// AllPriority returns all valid Priority values.
+func AllPriority() []Priority {
+	return []Priority{Priority1, Priority2, Priority3}
+}
+
+// Valid returns true if v is one of the Priority values.
+func (v Priority) Valid() bool {
+	switch v {
+	case Priority1, Priority2, Priority3:
+		return true
+	}
+	return false
+}
+
+func (v *Priority) UnmarshalJSON(b []byte) error {
+	var value int
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !Priority(value).Valid() {
+		return fmt.Errorf("invalid Priority %v", value)
+	}
+	*v = Priority(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the Priority values.
+func (m Priority) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m Priority) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllPriority())
+	}
+}
+
+// ParsePriority parses a Priority from its string representation.
+func ParsePriority(s string) (Priority, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	v := Priority(i)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid Priority %q", s)
+	}
+	return v, nil
+}
+
+type Push struct {
+	NotificationBase
+	Badge *int `json:"badge,omitempty" xml:"badge,omitempty"`
+}
+
+// Validate returns an error if m does not match the Push schema.
+func (m *Push) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Push) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	m.NotificationBase.validate(v, path)
+}
+
+type Task struct {
+	Done     *bool      `json:"done,omitempty" xml:"done,omitempty"`
+	Estimate *float32   `json:"estimate,omitempty" xml:"estimate,omitempty"`
+	Owner    *User      `json:"owner,omitempty" xml:"owner,omitempty"`
+	Priority *Priority  `json:"priority,omitempty" xml:"priority,omitempty"`
+	State    *TaskState `json:"state,omitempty" xml:"state,omitempty"`
+	Subtasks []*Task    `json:"subtasks,omitempty" xml:"subtasks,omitempty"`
+	Tags     []string   `json:"tags,omitempty" xml:"tags,omitempty"`
+	Title    string     `json:"title" xml:"title"`
+}
+
+// Validate returns an error if m does not match the Task schema.
+func (m *Task) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Task) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Owner != nil {
+		m.Owner.validate(v, field(path, "owner"))
+	}
+	if m.Priority != nil {
+		m.Priority.validate(v, field(path, "priority"))
+	}
+	if m.State != nil {
+		m.State.validate(v, field(path, "state"))
+	}
+	for i0, item0 := range m.Subtasks {
+		if item0 != nil {
+			item0.validate(v, index(field(path, "subtasks"), i0))
+		}
+	}
+}
+
+// ApplyDefaults sets the defaults of the Task schema for absent fields.
+func (m *Task) ApplyDefaults() {
+	if m == nil {
+		return
+	}
+	if m.Done == nil {
+		value := false
+		m.Done = &value
+	}
+	if m.Estimate == nil {
+		value := float32(1.5)
+		m.Estimate = &value
+	}
+	m.Owner.ApplyDefaults()
+	if m.Priority == nil {
+		value := Priority2
+		m.Priority = &value
+	}
+	if m.State == nil {
+		value := TaskStateOpen
+		m.State = &value
+	}
+	for _, item0 := range m.Subtasks {
+		item0.ApplyDefaults()
+	}
+	if m.Tags == nil {
+		m.Tags = []string{"todo"}
+	}
+}
+
+type TaskState string
+
+const (
+	TaskStateOpen TaskState = "open"
+	TaskStateDone TaskState = "done"
+)
+
+// AllTaskState returns all valid TaskState values.
+func AllTaskState() []TaskState {
+	return []TaskState{TaskStateOpen, TaskStateDone}
+}
+
+// Valid returns true if v is one of the TaskState values.
+func (v TaskState) Valid() bool {
+	switch v {
+	case TaskStateOpen, TaskStateDone:
+		return true
+	}
+	return false
+}
+
+func (v *TaskState) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !TaskState(value).Valid() {
+		return fmt.Errorf("invalid TaskState %v", value)
+	}
+	*v = TaskState(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the TaskState values.
+func (m TaskState) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m TaskState) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllTaskState())
+	}
+}
+
+// ParseTaskState parses a TaskState from its string representation.
+func ParseTaskState(s string) (TaskState, error) {
+	v := TaskState(s)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid TaskState %q", s)
+	}
+	return v, nil
+}
+
+type User struct {
+	Person
+	Role *string `json:"role,omitempty" xml:"role,omitempty"`
+}
+
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	m.Person.validate(v, path)
+}
+
+// ApplyDefaults sets the defaults of the User schema for absent fields.
+func (m *User) ApplyDefaults() {
+	if m == nil {
+		return
+	}
+	m.Person.ApplyDefaults()
+	if m.Role == nil {
+		value := "member"
+		m.Role = &value
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "ListTasks",
+		Args: Args{Method: "Get", URL: "/tasks/1"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "ListUsers",
+		Args: Args{Method: "Get", URL: "/users?role=admin&sort=asc"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+}
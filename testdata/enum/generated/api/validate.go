@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+)
+
+// operationSpec contains the declared responses of an operation.
+type operationSpec struct {
+	produces  []string
+	responses map[string]responseSpec // by status code, range like 4XX or default
+}
+
+// responseSpec describes the body of a declared response.
+type responseSpec struct {
+	body func() interface{} // returns a pointer to decode the body into, nil for responses without body
+	file bool
+}
+
+// validateResponses returns a middleware that checks the responses of the
+// operations against their specs, which are keyed by method and route
+// pattern. Violations are logged, if fail is set the response is replaced
+// by a problem with the status 500. Problem responses of errors are not
+// checked. File responses are streamed without being checked.
+func validateResponses(specs map[string]*operationSpec, fail bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &responseRecorder{w: w, header: http.Header{}, status: http.StatusOK}
+			rec.stream = func(status int) bool {
+				// the route is known once the handler writes
+				spec, ok := specs[r.Method+" "+routePattern(r)]
+				return ok && streamsFile(spec, status)
+			}
+			next.ServeHTTP(rec, r)
+			if rec.streaming {
+				return
+			}
+
+			var violations []string
+			if spec, ok := specs[r.Method+" "+routePattern(r)]; ok {
+				violations = checkResponse(spec, rec)
+			}
+			if len(violations) > 0 {
+				log.Printf("invalid response of %s %s: %s", r.Method, r.URL.Path, strings.Join(violations, "; "))
+			}
+
+			if fail && len(violations) > 0 {
+				p := NewProblem(http.StatusInternalServerError, "invalid_response", "the response does not match the spec")
+				WriteProblem(w, p.WithExtension("errors", violations))
+				return
+			}
+
+			for key, values := range rec.header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+		})
+	}
+}
+
+// routePattern returns the pattern of the innermost router that handled the
+// request.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || len(rctx.RoutePatterns) == 0 {
+		return ""
+	}
+	return rctx.RoutePatterns[len(rctx.RoutePatterns)-1]
+}
+
+// checkResponse returns the violations of the spec by a recorded response.
+func checkResponse(spec *operationSpec, rec *responseRecorder) []string {
+	mediaType, _, _ := mime.ParseMediaType(rec.header.Get("Content-Type"))
+	if mediaType == "application/problem+json" {
+		return nil
+	}
+
+	response, ok := declaredResponse(spec, rec.status)
+	if !ok {
+		return []string{fmt.Sprintf("undeclared status %d", rec.status)}
+	}
+
+	switch {
+	case response.body == nil && !response.file:
+		if rec.body.Len() > 0 {
+			return []string{fmt.Sprintf("unexpected body of response %d", rec.status)}
+		}
+		return nil
+	case rec.body.Len() == 0:
+		return []string{fmt.Sprintf("missing body of response %d", rec.status)}
+	case !containsMediaType(spec.produces, mediaType):
+		return []string{fmt.Sprintf("undeclared content type %q", mediaType)}
+	case response.file:
+		return nil
+	}
+
+	codec, ok := lookupCodec(mediaType)
+	if !ok {
+		return []string{fmt.Sprintf("no codec for content type %q", mediaType)}
+	}
+	v := response.body()
+	if err := codec.Decode(bytes.NewReader(rec.body.Bytes()), v); err != nil {
+		return []string{fmt.Sprintf("body does not match the schema: %s", err)}
+	}
+	if err := validateValue(reflect.ValueOf(v)); err != nil {
+		var messages interface{ Messages() []string }
+		if errors.As(err, &messages) {
+			return messages.Messages()
+		}
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+// declaredResponse returns the response of the status code, the response of
+// its range or the default response.
+func declaredResponse(spec *operationSpec, status int) (responseSpec, bool) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if response, ok := spec.responses[key]; ok {
+			return response, true
+		}
+	}
+	return responseSpec{}, false
+}
+
+// streamsFile reports if a response with the status is a file. Partial
+// content, not modified and unsatisfiable range responses are written by
+// http.ServeContent for files of 200 responses.
+func streamsFile(spec *operationSpec, status int) bool {
+	if response, ok := declaredResponse(spec, status); ok && response.file {
+		return true
+	}
+	switch status {
+	case http.StatusPartialContent, http.StatusNotModified, http.StatusRequestedRangeNotSatisfiable:
+		response, ok := spec.responses[strconv.Itoa(http.StatusOK)]
+		return ok && response.file
+	}
+	return false
+}
+
+// validateValue calls the Validate methods of a decoded value or of its
+// items.
+func validateValue(v reflect.Value) error {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	if validator, ok := v.Interface().(interface{ Validate() error }); ok {
+		return validator.Validate()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			return validateValue(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateValue(iter.Value()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// responseRecorder buffers a response, so it can be checked before it is
+// written. Responses of a status that stream returns true for are passed
+// through to w instead.
+type responseRecorder struct {
+	w         http.ResponseWriter
+	stream    func(status int) bool
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	wrote     bool
+	streaming bool
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wrote {
+		return
+	}
+	r.status, r.wrote = status, true
+
+	if r.stream != nil && r.stream(status) {
+		for key, values := range r.header {
+			r.w.Header()[key] = values
+		}
+		r.w.WriteHeader(status)
+		r.streaming = true
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	if r.streaming {
+		return r.w.Write(b)
+	}
+	return r.body.Write(b)
+}
+
+// Flush flushes streamed responses. Buffered responses are written after
+// the check.
+func (r *responseRecorder) Flush() {
+	if f, ok := r.w.(http.Flusher); ok && r.streaming {
+		f.Flush()
+	}
+}
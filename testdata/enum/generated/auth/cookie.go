@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	clock "github.com/cugu/swagger-go-chi/testdata/enum/generated/time"
+)
+
+var (
+	ErrInvalidCookie = errors.New("invalid cookie")
+	ErrExpiredCookie = errors.New("expired cookie")
+)
+
+// Cookies encrypts and authenticates the values of cookies with AES-GCM.
+// New cookies are encrypted with the first key, all keys are tried for
+// decryption, so keys can be rotated by adding a new key in front and
+// removing the old key once its cookies expired.
+type Cookies struct {
+	Secure   bool          // only send the cookies over HTTPS
+	SameSite http.SameSite // lax by default, so the cookies are sent on the redirect from the identity provider
+	Clock    clock.Clock   // checks the expiry, clock.DefaultClock if nil
+
+	aeads []cipher.AEAD
+}
+
+// NewCookies returns secure cookies encrypted with the keys, which must have
+// 16, 24 or 32 bytes.
+func NewCookies(keys ...[]byte) (*Cookies, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no cookie key")
+	}
+
+	c := &Cookies{Secure: true, SameSite: http.SameSiteLaxMode}
+	for i, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("cookie key %d: %w", i, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("cookie key %d: %w", i, err)
+		}
+		c.aeads = append(c.aeads, aead)
+	}
+	return c, nil
+}
+
+// DecodeKeys decodes base64 encoded cookie keys.
+func DecodeKeys(encoded ...string) ([][]byte, error) {
+	var keys [][]byte
+	for i, s := range encoded {
+		key, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("cookie key %d: %w", i, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// cookieValue is the encrypted content of a cookie.
+type cookieValue struct {
+	Expires int64           `json:"exp,omitempty"` // unix time, 0 for session cookies
+	Value   json.RawMessage `json:"v"`
+}
+
+// Set writes the value as JSON into an encrypted cookie that expires after
+// maxAge. A maxAge of 0 sets a session cookie, which has no expiry and is
+// removed when the browser is closed.
+func (c *Cookies) Set(w http.ResponseWriter, name string, value interface{}, maxAge time.Duration) error {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	content := cookieValue{Value: v}
+	var expires time.Time
+	if maxAge > 0 {
+		expires = c.now().Add(maxAge)
+		content.Expires = expires.Unix()
+	}
+	plaintext, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+
+	aead := c.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	// the name is authenticated, so the value cannot be moved to another cookie
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(name))
+
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    base64.RawURLEncoding.EncodeToString(sealed),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.Secure,
+		SameSite: c.SameSite,
+	}
+	if maxAge > 0 {
+		cookie.Expires, cookie.MaxAge = expires, int(maxAge.Seconds())
+	}
+	http.SetCookie(w, cookie)
+	return nil
+}
+
+// Get decrypts the cookie and decodes its JSON value into value. It returns
+// http.ErrNoCookie if the cookie is missing, ErrInvalidCookie if it cannot be
+// decrypted with any of the keys and ErrExpiredCookie if it expired.
+func (c *Cookies) Get(r *http.Request, name string, value interface{}) error {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return ErrInvalidCookie
+	}
+
+	plaintext, ok := c.open(name, sealed)
+	if !ok {
+		return ErrInvalidCookie
+	}
+
+	var v cookieValue
+	if err := json.Unmarshal(plaintext, &v); err != nil {
+		return ErrInvalidCookie
+	}
+	if v.Expires != 0 && !c.now().Before(time.Unix(v.Expires, 0)) {
+		return ErrExpiredCookie
+	}
+	return json.Unmarshal(v.Value, value)
+}
+
+func (c *Cookies) open(name string, sealed []byte) ([]byte, bool) {
+	for _, aead := range c.aeads {
+		if len(sealed) < aead.NonceSize() {
+			continue
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(name)); err == nil {
+			return plaintext, true
+		}
+	}
+	return nil, false
+}
+
+// Delete removes the cookie.
+func (c *Cookies) Delete(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.Secure,
+		SameSite: c.SameSite,
+	})
+}
+
+func (c *Cookies) now() time.Time {
+	if c.Clock == nil {
+		return clock.Now()
+	}
+	return c.Clock.Now()
+}
@@ -0,0 +1,495 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type ListTasksPriority int
+
+const (
+	ListTasksPriority1 ListTasksPriority = 1
+	ListTasksPriority2 ListTasksPriority = 2
+	ListTasksPriority3 ListTasksPriority = 3
+)
+
+// AllListTasksPriority returns all valid ListTasksPriority values.
+func AllListTasksPriority() []ListTasksPriority {
+	return []ListTasksPriority{ListTasksPriority1, ListTasksPriority2, ListTasksPriority3}
+}
+
+// Valid returns true if v is one of the ListTasksPriority values.
+func (v ListTasksPriority) Valid() bool {
+	switch v {
+	case ListTasksPriority1, ListTasksPriority2, ListTasksPriority3:
+		return true
+	}
+	return false
+}
+
+func (v *ListTasksPriority) UnmarshalJSON(b []byte) error {
+	var value int
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !ListTasksPriority(value).Valid() {
+		return fmt.Errorf("invalid ListTasksPriority %v", value)
+	}
+	*v = ListTasksPriority(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the ListTasksPriority values.
+func (m ListTasksPriority) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m ListTasksPriority) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllListTasksPriority())
+	}
+}
+
+// ParseListTasksPriority parses a ListTasksPriority from its string representation.
+func ParseListTasksPriority(s string) (ListTasksPriority, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	v := ListTasksPriority(i)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid ListTasksPriority %q", s)
+	}
+	return v, nil
+}
+
+type ListUsersRole string
+
+const (
+	ListUsersRoleAdmin ListUsersRole = "admin"
+	ListUsersRoleUser  ListUsersRole = "user"
+)
+
+// AllListUsersRole returns all valid ListUsersRole values.
+func AllListUsersRole() []ListUsersRole {
+	return []ListUsersRole{ListUsersRoleAdmin, ListUsersRoleUser}
+}
+
+// Valid returns true if v is one of the ListUsersRole values.
+func (v ListUsersRole) Valid() bool {
+	switch v {
+	case ListUsersRoleAdmin, ListUsersRoleUser:
+		return true
+	}
+	return false
+}
+
+func (v *ListUsersRole) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !ListUsersRole(value).Valid() {
+		return fmt.Errorf("invalid ListUsersRole %v", value)
+	}
+	*v = ListUsersRole(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the ListUsersRole values.
+func (m ListUsersRole) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m ListUsersRole) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllListUsersRole())
+	}
+}
+
+// ParseListUsersRole parses a ListUsersRole from its string representation.
+func ParseListUsersRole(s string) (ListUsersRole, error) {
+	v := ListUsersRole(s)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid ListUsersRole %q", s)
+	}
+	return v, nil
+}
+
+type ListUsersSort string
+
+const (
+	ListUsersSortAsc  ListUsersSort = "asc"
+	ListUsersSortDesc ListUsersSort = "desc"
+)
+
+// AllListUsersSort returns all valid ListUsersSort values.
+func AllListUsersSort() []ListUsersSort {
+	return []ListUsersSort{ListUsersSortAsc, ListUsersSortDesc}
+}
+
+// Valid returns true if v is one of the ListUsersSort values.
+func (v ListUsersSort) Valid() bool {
+	switch v {
+	case ListUsersSortAsc, ListUsersSortDesc:
+		return true
+	}
+	return false
+}
+
+func (v *ListUsersSort) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !ListUsersSort(value).Valid() {
+		return fmt.Errorf("invalid ListUsersSort %v", value)
+	}
+	*v = ListUsersSort(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the ListUsersSort values.
+func (m ListUsersSort) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m ListUsersSort) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllListUsersSort())
+	}
+}
+
+// ParseListUsersSort parses a ListUsersSort from its string representation.
+func ParseListUsersSort(s string) (ListUsersSort, error) {
+	v := ListUsersSort(s)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid ListUsersSort %q", s)
+	}
+	return v, nil
+}
+
+type ListUsersStatusItem string
+
+const (
+	ListUsersStatusItemActive  ListUsersStatusItem = "active"
+	ListUsersStatusItemBlocked ListUsersStatusItem = "blocked"
+)
+
+// AllListUsersStatusItem returns all valid ListUsersStatusItem values.
+func AllListUsersStatusItem() []ListUsersStatusItem {
+	return []ListUsersStatusItem{ListUsersStatusItemActive, ListUsersStatusItemBlocked}
+}
+
+// Valid returns true if v is one of the ListUsersStatusItem values.
+func (v ListUsersStatusItem) Valid() bool {
+	switch v {
+	case ListUsersStatusItemActive, ListUsersStatusItemBlocked:
+		return true
+	}
+	return false
+}
+
+func (v *ListUsersStatusItem) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !ListUsersStatusItem(value).Valid() {
+		return fmt.Errorf("invalid ListUsersStatusItem %v", value)
+	}
+	*v = ListUsersStatusItem(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the ListUsersStatusItem values.
+func (m ListUsersStatusItem) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m ListUsersStatusItem) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllListUsersStatusItem())
+	}
+}
+
+// ParseListUsersStatusItem parses a ListUsersStatusItem from its string representation.
+func ParseListUsersStatusItem(s string) (ListUsersStatusItem, error) {
+	v := ListUsersStatusItem(s)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid ListUsersStatusItem %q", s)
+	}
+	return v, nil
+}
+
+type Priority int32
+
+const (
+	Priority1 Priority = 1
+	Priority2 Priority = 2
+	Priority3 Priority = 3
+)
+
+// AllPriority returns all valid Priority values.
+func AllPriority() []Priority {
+	return []Priority{Priority1, Priority2, Priority3}
+}
+
+// Valid returns true if v is one of the Priority values.
+func (v Priority) Valid() bool {
+	switch v {
+	case Priority1, Priority2, Priority3:
+		return true
+	}
+	return false
+}
+
+func (v *Priority) UnmarshalJSON(b []byte) error {
+	var value int32
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !Priority(value).Valid() {
+		return fmt.Errorf("invalid Priority %v", value)
+	}
+	*v = Priority(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the Priority values.
+func (m Priority) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m Priority) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllPriority())
+	}
+}
+
+// ParsePriority parses a Priority from its string representation.
+func ParsePriority(s string) (Priority, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	v := Priority(i)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid Priority %q", s)
+	}
+	return v, nil
+}
+
+type User struct {
+	Name     string         `json:"name" xml:"name"`
+	Priority *Priority      `json:"priority,omitempty" xml:"priority,omitempty"`
+	Role     UserRole       `json:"role" xml:"role"`
+	Tags     []UserTagsItem `json:"tags,omitempty" xml:"tags,omitempty"`
+	Weight   *UserWeight    `json:"weight,omitempty" xml:"weight,omitempty"`
+}
+
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Priority != nil {
+		m.Priority.validate(v, field(path, "priority"))
+	}
+	m.Role.validate(v, field(path, "role"))
+	for i0, item0 := range m.Tags {
+		item0.validate(v, index(field(path, "tags"), i0))
+	}
+	if m.Weight != nil {
+		m.Weight.validate(v, field(path, "weight"))
+	}
+}
+
+type UserRole string
+
+const (
+	UserRoleAdmin    UserRole = "admin"
+	UserRoleUser     UserRole = "user"
+	UserRoleReadOnly UserRole = "read-only"
+)
+
+// AllUserRole returns all valid UserRole values.
+func AllUserRole() []UserRole {
+	return []UserRole{UserRoleAdmin, UserRoleUser, UserRoleReadOnly}
+}
+
+// Valid returns true if v is one of the UserRole values.
+func (v UserRole) Valid() bool {
+	switch v {
+	case UserRoleAdmin, UserRoleUser, UserRoleReadOnly:
+		return true
+	}
+	return false
+}
+
+func (v *UserRole) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !UserRole(value).Valid() {
+		return fmt.Errorf("invalid UserRole %v", value)
+	}
+	*v = UserRole(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the UserRole values.
+func (m UserRole) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m UserRole) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllUserRole())
+	}
+}
+
+// ParseUserRole parses a UserRole from its string representation.
+func ParseUserRole(s string) (UserRole, error) {
+	v := UserRole(s)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid UserRole %q", s)
+	}
+	return v, nil
+}
+
+type UserTagsItem string
+
+const (
+	UserTagsItemNew UserTagsItem = "new"
+	UserTagsItemVip UserTagsItem = "vip"
+)
+
+// AllUserTagsItem returns all valid UserTagsItem values.
+func AllUserTagsItem() []UserTagsItem {
+	return []UserTagsItem{UserTagsItemNew, UserTagsItemVip}
+}
+
+// Valid returns true if v is one of the UserTagsItem values.
+func (v UserTagsItem) Valid() bool {
+	switch v {
+	case UserTagsItemNew, UserTagsItemVip:
+		return true
+	}
+	return false
+}
+
+func (v *UserTagsItem) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !UserTagsItem(value).Valid() {
+		return fmt.Errorf("invalid UserTagsItem %v", value)
+	}
+	*v = UserTagsItem(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the UserTagsItem values.
+func (m UserTagsItem) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m UserTagsItem) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllUserTagsItem())
+	}
+}
+
+// ParseUserTagsItem parses a UserTagsItem from its string representation.
+func ParseUserTagsItem(s string) (UserTagsItem, error) {
+	v := UserTagsItem(s)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid UserTagsItem %q", s)
+	}
+	return v, nil
+}
+
+type UserWeight float64
+
+const (
+	UserWeight0Point5 UserWeight = 0.5
+	UserWeight1       UserWeight = 1
+	UserWeightMinus1  UserWeight = -1
+)
+
+// AllUserWeight returns all valid UserWeight values.
+func AllUserWeight() []UserWeight {
+	return []UserWeight{UserWeight0Point5, UserWeight1, UserWeightMinus1}
+}
+
+// Valid returns true if v is one of the UserWeight values.
+func (v UserWeight) Valid() bool {
+	switch v {
+	case UserWeight0Point5, UserWeight1, UserWeightMinus1:
+		return true
+	}
+	return false
+}
+
+func (v *UserWeight) UnmarshalJSON(b []byte) error {
+	var value float64
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !UserWeight(value).Valid() {
+		return fmt.Errorf("invalid UserWeight %v", value)
+	}
+	*v = UserWeight(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the UserWeight values.
+func (m UserWeight) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m UserWeight) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllUserWeight())
+	}
+}
+
+// ParseUserWeight parses a UserWeight from its string representation.
+func ParseUserWeight(s string) (UserWeight, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	v := UserWeight(f)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid UserWeight %q", s)
+	}
+	return v, nil
+}
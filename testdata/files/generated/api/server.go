@@ -0,0 +1,222 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/cugu/swagger-go-chi/testdata/files/generated/model"
+)
+
+type Service interface {
+	AddAttachment(context.Context, []*multipart.FileHeader) error
+	DownloadFile(context.Context, string) (*File, error)
+	UploadFile(context.Context, string, io.Reader) error
+	GetImage(context.Context, string) (GetImageResponse, error)
+}
+
+// securitySchemes are the securityDefinitions of the spec.
+var securitySchemes = map[string]SecurityScheme{}
+
+// NewServer returns the router of the API. Requests are authenticated by the
+// authenticate middleware, if it is set, and the security requirements of
+// the operations are checked by the authorizer. Public operations, which
+// have an empty list of security requirements, skip both. The errors of the
+// service are mapped to problems by the errorMapper, if it is set, and by
+// MapErrors.
+func NewServer(service Service, authenticate func(http.Handler) http.Handler, authorizer Authorizer, errorMapper ErrorMapper, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	if authenticate == nil {
+		authenticate = NilMiddleware()
+	}
+
+	s := &server{service, errorMapper}
+
+	r.With(authenticate).Post("/attachments", s.addAttachmentHandler)
+	r.With(authenticate).Get("/files/{name}", s.downloadFileHandler)
+	r.With(authenticate).Put("/files/{name}", s.uploadFileHandler)
+	r.With(authenticate).Get("/images/{id}", s.getImageHandler)
+	return r
+}
+
+// ValidateResponses returns a middleware that checks the responses of the
+// operations against the spec. Violations are logged, if fail is set the
+// response is replaced by a problem with the status 500.
+func ValidateResponses(fail bool) func(http.Handler) http.Handler {
+	return validateResponses(operationSpecs, fail)
+}
+
+// operationSpecs are the declared responses of the operations by method and
+// route pattern.
+var operationSpecs = map[string]*operationSpec{
+	"POST /attachments": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+	"GET /files/{name}": {
+		produces: []string{"application/octet-stream"},
+		responses: map[string]responseSpec{
+			"200": {file: true},
+		},
+	},
+	"PUT /files/{name}": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+	"GET /images/{id}": {
+		produces: []string{"image/png", "application/json"},
+		responses: map[string]responseSpec{
+			"200": {file: true},
+			"404": {body: func() interface{} { return new(*model.Error) }},
+		},
+	},
+}
+
+type server struct {
+	service     Service
+	errorMapper ErrorMapper
+}
+
+// serviceError maps an error of the service to a problem.
+func (s *server) serviceError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s.errorMapper != nil {
+		if p := s.errorMapper(err); p != nil {
+			return p
+		}
+	}
+	if p := MapErrors(err); p != nil {
+		return p
+	}
+	return err
+}
+
+// MapErrors maps the sentinel errors and error types of the model package
+// to problems, other errors are mapped to nil.
+func MapErrors(err error) *Problem {
+	var validationErrors model.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+		return p.WithExtension("errors", validationErrors.Messages())
+	}
+
+	var status int
+	switch {
+	case errors.Is(err, model.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, model.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, model.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, model.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, model.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	default:
+		return nil
+	}
+
+	p := NewProblem(status, "", err.Error()).WithCause(err)
+	var coded *model.CodedError
+	if errors.As(err, &coded) {
+		p.Code = coded.Code
+	}
+	return p
+}
+
+func (s *server) addAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 10485760)
+	if _, err := requestMediaType(r, []string{"multipart/form-data", "application/x-www-form-urlencoded"}); err != nil {
+		bodyError(w, err)
+		return
+	}
+	if err := r.ParseMultipartForm(formMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		bodyError(w, err)
+		return
+	}
+	fileP := formFiles(r, "file")
+
+	response(w, nil, nil, s.serviceError(s.service.AddAttachment(r.Context(), fileP)))
+}
+
+func (s *server) downloadFileHandler(w http.ResponseWriter, r *http.Request) {
+	nameP := chi.URLParam(r, "name")
+
+	result, err := s.service.DownloadFile(r.Context(), nameP)
+	fileResponse(w, r, result, s.serviceError(err))
+}
+
+func (s *server) uploadFileHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1073741824)
+	nameP := chi.URLParam(r, "name")
+
+	if _, err := requestMediaType(r, []string{"application/octet-stream"}); err != nil {
+		bodyError(w, err)
+		return
+	}
+	contentP := r.Body
+
+	response(w, nil, nil, s.serviceError(s.service.UploadFile(r.Context(), nameP, contentP)))
+}
+
+func (s *server) getImageHandler(w http.ResponseWriter, r *http.Request) {
+	// files are written in their own media type, so the negotiation
+	// only selects the media type of the other responses
+	enc, _ := negotiate(r, []string{"image/png", "application/json"})
+	idP := chi.URLParam(r, "id")
+
+	result, err := s.service.GetImage(r.Context(), idP)
+	respond(w, r, enc, result, s.serviceError(err))
+}
+
+// GetImageResponse is one of the responses of the getImage operation.
+type GetImageResponse interface {
+	responder
+	isGetImageResponse()
+}
+
+// GetImage200Response is the 200 response of the getImage operation.
+type GetImage200Response struct {
+	Body *File
+}
+
+// GetImageResponse200 returns the 200 response of the getImage operation.
+func GetImageResponse200(body *File) *GetImage200Response {
+	return &GetImage200Response{Body: body}
+}
+
+func (*GetImage200Response) isGetImageResponse() {}
+
+func (r *GetImage200Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	serveFile(w, req, 200, r.Body)
+}
+
+// GetImage404Response is the 404 response of the getImage operation.
+type GetImage404Response struct {
+	Body *model.Error
+}
+
+// GetImageResponse404 returns the 404 response of the getImage operation.
+func GetImageResponse404(body *model.Error) *GetImage404Response {
+	return &GetImage404Response{Body: body}
+}
+
+func (*GetImage404Response) isGetImageResponse() {}
+
+func (r *GetImage404Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	e.write(w, 404, r.Body)
+}
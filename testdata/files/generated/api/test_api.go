@@ -0,0 +1,54 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "AddAttachment",
+		Args: Args{Method: "Post", URL: "/attachments"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "DownloadFile",
+		Args: Args{Method: "Get", URL: "/files/%7Bname%7D"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "UploadFile",
+		Args: Args{Method: "Put", URL: "/files/%7Bname%7D"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "GetImage",
+		Args: Args{Method: "Get", URL: "/images/%7Bid%7D"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+}
@@ -0,0 +1,19 @@
+package model
+
+type Error struct {
+	Message *string `json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// Validate returns an error if m does not match the Error schema.
+func (m *Error) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Error) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
@@ -3,7 +3,6 @@ package api
 import (
 	"context"
 	"errors"
-	"io"
 	"mime/multipart"
 	"net/http"
 
@@ -13,37 +12,140 @@ import (
 )
 
 type Service interface {
-	UploadFile(context.Context, []*multipart.FileHeader, []string) error
+	UploadFile(context.Context, []*multipart.FileHeader, []string) (UploadFileResponse, error)
 }
 
-func NewServer(service Service, roleAuth func([]string) func(http.Handler) http.Handler, middlewares ...func(http.Handler) http.Handler) chi.Router {
+// securitySchemes are the securityDefinitions of the spec.
+var securitySchemes = map[string]SecurityScheme{}
+
+// NewServer returns the router of the API. Requests are authenticated by the
+// authenticate middleware, if it is set, and the security requirements of
+// the operations are checked by the authorizer. Public operations, which
+// have an empty list of security requirements, skip both. The errors of the
+// service are mapped to problems by the errorMapper, if it is set, and by
+// MapErrors.
+func NewServer(service Service, authenticate func(http.Handler) http.Handler, authorizer Authorizer, errorMapper ErrorMapper, middlewares ...func(http.Handler) http.Handler) chi.Router {
 	r := chi.NewRouter()
 	r.Use(middlewares...)
 
-	s := &server{service}
+	if authenticate == nil {
+		authenticate = NilMiddleware()
+	}
+
+	s := &server{service, errorMapper}
 
-	r.With(roleAuth([]string{"uploadSystemData"})).Put("/file", s.uploadFileHandler)
+	r.With(authenticate, authorize(authorizer, securitySchemes, []SecurityRequirement{{"roles": []string{"uploadSystemData"}}})).Put("/file", s.uploadFileHandler)
 	return r
 }
 
+// ValidateResponses returns a middleware that checks the responses of the
+// operations against the spec. Violations are logged, if fail is set the
+// response is replaced by a problem with the status 500.
+func ValidateResponses(fail bool) func(http.Handler) http.Handler {
+	return validateResponses(operationSpecs, fail)
+}
+
+// operationSpecs are the declared responses of the operations by method and
+// route pattern.
+var operationSpecs = map[string]*operationSpec{
+	"PUT /file": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"201": {},
+		},
+	},
+}
+
 type server struct {
-	service Service
+	service     Service
+	errorMapper ErrorMapper
+}
+
+// serviceError maps an error of the service to a problem.
+func (s *server) serviceError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s.errorMapper != nil {
+		if p := s.errorMapper(err); p != nil {
+			return p
+		}
+	}
+	if p := MapErrors(err); p != nil {
+		return p
+	}
+	return err
+}
+
+// MapErrors maps the sentinel errors and error types of the model package
+// to problems, other errors are mapped to nil.
+func MapErrors(err error) *Problem {
+	var validationErrors model.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+		return p.WithExtension("errors", validationErrors.Messages())
+	}
+
+	var status int
+	switch {
+	case errors.Is(err, model.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, model.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, model.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, model.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, model.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	default:
+		return nil
+	}
+
+	p := NewProblem(status, "", err.Error()).WithCause(err)
+	var coded *model.CodedError
+	if errors.As(err, &coded) {
+		p.Code = coded.Code
+	}
+	return p
 }
 
 func (s *server) uploadFileHandler(w http.ResponseWriter, r *http.Request) {
-	const MaxFileSize = 32 << 20 // maximum file size of about 32 MB
-	if r.ContentLength > MaxFileSize {
-		JSONErrorStatus(w, http.StatusExpectationFailed, errors.New("request too large"))
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+	if _, err := requestMediaType(r, []string{"multipart/form-data"}); err != nil {
+		bodyError(w, err)
 		return
 	}
-	err := r.ParseMultipartForm(MaxFileSize)
-	if err != nil {
-		JSONError(w, err)
+	if err := r.ParseMultipartForm(formMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		bodyError(w, err)
 		return
 	}
-	uploadP := r.MultipartForm.File["upload"]
+	uploadP := formFiles(r, "upload")
+
+	metadataP := r.PostForm["metadata"]
+
+	result, err := s.service.UploadFile(r.Context(), uploadP, metadataP)
+	respond(w, r, nil, result, s.serviceError(err))
+}
+
+// UploadFileResponse is one of the responses of the uploadFile operation.
+type UploadFileResponse interface {
+	responder
+	isUploadFileResponse()
+}
+
+// UploadFile201Response is the 201 response of the uploadFile operation.
+type UploadFile201Response struct{}
+
+// UploadFileResponse201 returns the 201 response of the uploadFile operation.
+func UploadFileResponse201() *UploadFile201Response {
+	return &UploadFile201Response{}
+}
 
-	metadataP := r.MultipartForm.Value["metadata"]
+func (*UploadFile201Response) isUploadFileResponse() {}
 
-	response(w, nil, s.service.UploadFile(r.Context(), uploadP, metadataP))
+func (r *UploadFile201Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	w.WriteHeader(201)
 }
@@ -0,0 +1,418 @@
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+type HTTPError struct {
+	Status   int
+	Internal error
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTPError(%d): %s", e.Status, e.Internal)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Internal
+}
+
+func parseURLValue[T any](r *http.Request, key string, parse func(string) (T, error)) (T, error) {
+	return parseValue(chi.URLParam(r, key), parse)
+}
+
+func parseQueryValue[T any](r *http.Request, key string, parse func(string) (T, error)) (T, error) {
+	values, ok := r.URL.Query()[key]
+	if !ok {
+		var v T
+		return v, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, fmt.Errorf("query parameter %s is required", key)})
+	}
+	return parseValue(values[0], parse)
+}
+
+func parseQueryOptionalValue[T any](r *http.Request, key string, parse func(string) (T, error)) (*T, error) {
+	values, ok := r.URL.Query()[key]
+	if !ok {
+		return nil, nil
+	}
+	v, err := parseValue(values[0], parse)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// parseQueryDefaultValue parses a query parameter or its default value if
+// the parameter is missing.
+func parseQueryDefaultValue[T any](r *http.Request, key, def string, parse func(string) (T, error)) (T, error) {
+	values, ok := r.URL.Query()[key]
+	if !ok {
+		return parseValue(def, parse)
+	}
+	return parseValue(values[0], parse)
+}
+
+// parseQueryValueArray parses the items of all occurrences of a query
+// parameter. The occurrences are split by the separator of the collection
+// format, the multi format has no separator. Empty items are ignored.
+func parseQueryValueArray[T any](r *http.Request, key, separator string, maxItems int, parse func(string) (T, error)) ([]T, error) {
+	var items []string
+	for _, value := range r.URL.Query()[key] {
+		if separator == "" {
+			items = append(items, value)
+		} else {
+			items = append(items, strings.Split(value, separator)...)
+		}
+	}
+	items = removeEmpty(items)
+	if len(items) > maxItems {
+		return nil, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, fmt.Errorf("too many items in query parameter %s", key)})
+	}
+
+	var values []T
+	for _, s := range items {
+		v, err := parseValue(s, parse)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func removeEmpty(l []string) []string {
+	var stringArray []string
+	for _, s := range l {
+		if s == "" {
+			continue
+		}
+		stringArray = append(stringArray, s)
+	}
+
+	return stringArray
+}
+
+func parseHeaderValue[T any](r *http.Request, key string, parse func(string) (T, error)) (T, error) {
+	values := r.Header.Values(key)
+	if len(values) == 0 {
+		var v T
+		return v, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, fmt.Errorf("header %s is required", key)})
+	}
+	return parseValue(values[0], parse)
+}
+
+func parseHeaderOptionalValue[T any](r *http.Request, key string, parse func(string) (T, error)) (*T, error) {
+	values := r.Header.Values(key)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	v, err := parseValue(values[0], parse)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// parseHeaderDefaultValue parses a header or its default value if the header
+// is missing.
+func parseHeaderDefaultValue[T any](r *http.Request, key, def string, parse func(string) (T, error)) (T, error) {
+	values := r.Header.Values(key)
+	if len(values) == 0 {
+		return parseValue(def, parse)
+	}
+	return parseValue(values[0], parse)
+}
+
+// parseHeaderValueArray parses the comma separated items of all lines of a
+// header.
+func parseHeaderValueArray[T any](r *http.Request, key string, parse func(string) (T, error)) ([]T, error) {
+	var values []T
+	for _, line := range r.Header.Values(key) {
+		for _, s := range strings.Split(line, ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			v, err := parseValue(s, parse)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+func parseCookieValue[T any](r *http.Request, key string, parse func(string) (T, error)) (T, error) {
+	cookie, err := r.Cookie(key)
+	if err != nil {
+		var v T
+		return v, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, fmt.Errorf("cookie %s is required", key)})
+	}
+	return parseValue(cookie.Value, parse)
+}
+
+func parseCookieOptionalValue[T any](r *http.Request, key string, parse func(string) (T, error)) (*T, error) {
+	cookie, err := r.Cookie(key)
+	if err != nil {
+		return nil, nil
+	}
+	v, err := parseValue(cookie.Value, parse)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// parseCookieDefaultValue parses a cookie or its default value if the cookie
+// is missing.
+func parseCookieDefaultValue[T any](r *http.Request, key, def string, parse func(string) (T, error)) (T, error) {
+	cookie, err := r.Cookie(key)
+	if err != nil {
+		return parseValue(def, parse)
+	}
+	return parseValue(cookie.Value, parse)
+}
+
+// parseCookieValueArray parses the comma separated items of a cookie.
+func parseCookieValueArray[T any](r *http.Request, key string, parse func(string) (T, error)) ([]T, error) {
+	cookie, err := r.Cookie(key)
+	if err != nil {
+		return nil, nil
+	}
+
+	var values []T
+	for _, s := range strings.Split(cookie.Value, ",") {
+		if s == "" {
+			continue
+		}
+		v, err := parseValue(s, parse)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// defaultValues returns the parsed default items of an array parameter if
+// no items were sent. The defaults are checked by the generator, so items
+// that cannot be parsed are skipped.
+func defaultValues[T any](values []T, def []string, parse func(string) (T, error)) []T {
+	if len(values) > 0 {
+		return values
+	}
+	for _, s := range def {
+		if v, err := parse(s); err == nil {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func parseValue[T any](s string, parse func(string) (T, error)) (T, error) {
+	v, err := parse(s)
+	if err != nil {
+		return v, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
+	}
+	return v, nil
+}
+
+// The parse functions convert single parameter values to the Go type of
+// their schema.
+
+func parseString(s string) (string, error) {
+	return s, nil
+}
+
+func parseInt(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+func parseInt32(s string) (int32, error) {
+	i, err := strconv.ParseInt(s, 10, 32)
+	return int32(i), err
+}
+
+func parseInt64(s string) (int64, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseFloat32(s string) (float32, error) {
+	f, err := strconv.ParseFloat(s, 32)
+	return float32(f), err
+}
+
+func parseFloat64(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseBool parses a boolean, a flag without value is true.
+func parseBool(s string) (bool, error) {
+	if s == "" {
+		return true, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+func parseDateTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
+
+func parseBytes(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(s)
+}
+
+func parseIP(s string) (net.IP, error) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip address %q", s)
+	}
+	return ip, nil
+}
+
+// formFiles returns the uploaded files of a form field, forms that are not
+// multipart have no files.
+func formFiles(r *http.Request, key string) []*multipart.FileHeader {
+	if r.MultipartForm == nil {
+		return nil
+	}
+	return r.MultipartForm.File[key]
+}
+
+// bodyError writes the error of decoding a request body, unsupported media
+// types and too large bodies are reported with their status.
+func bodyError(w http.ResponseWriter, err error) {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		JSONErrorStatus(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	JSONError(w, err)
+}
+
+// JSONError writes the error of an invalid request as problem. HTTP errors
+// keep their status, other errors have the status 400.
+func JSONError(w http.ResponseWriter, err error) {
+	var httpError *HTTPError
+	if errors.As(err, &httpError) {
+		JSONErrorStatus(w, httpError.Status, httpError.Internal)
+		return
+	}
+	JSONErrorStatus(w, http.StatusBadRequest, err)
+}
+
+// JSONErrorStatus writes the error as problem with the status.
+func JSONErrorStatus(w http.ResponseWriter, status int, err error) {
+	WriteProblem(w, NewProblem(status, "", err.Error()).WithCause(err))
+}
+
+// response writes the result of a service in the negotiated media type or
+// its error as problem.
+func response(w http.ResponseWriter, e *encoder, v interface{}, err error) {
+	if err != nil {
+		WriteProblem(w, problem(err))
+		return
+	}
+
+	if v == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	e.write(w, http.StatusOK, v)
+}
+
+// emptyResponse writes the status of a response without body or the error
+// of a service as problem.
+func emptyResponse(w http.ResponseWriter, status int, err error) {
+	if err != nil {
+		WriteProblem(w, problem(err))
+		return
+	}
+	w.WriteHeader(status)
+}
+
+// responder is implemented by the response types of operations with
+// multiple responses.
+type responder interface {
+	writeResponse(w http.ResponseWriter, r *http.Request, e *encoder)
+}
+
+// respond writes the response returned by a service or its error.
+func respond(w http.ResponseWriter, r *http.Request, e *encoder, res responder, err error) {
+	if err != nil {
+		response(w, e, nil, err)
+		return
+	}
+	if res == nil {
+		JSONErrorStatus(w, http.StatusInternalServerError, errors.New("no response"))
+		return
+	}
+	res.writeResponse(w, r, e)
+}
+
+// formatValue returns the string representation of a response header value.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// formatValues joins the string representations of the items of an array
+// response header.
+func formatValues[T any](values []T, separator string) string {
+	items := make([]string, 0, len(values))
+	for _, v := range values {
+		items = append(items, formatValue(v))
+	}
+	return strings.Join(items, separator)
+}
+
+// validateBody writes the validation errors of a decoded request body and
+// returns true if the body is invalid.
+func validateBody(w http.ResponseWriter, body interface{ Validate() error }) bool {
+	return validationError(w, body.Validate())
+}
+
+// validationError writes the validation errors of a request and returns true
+// if there are any.
+func validationError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	validationErrors := []string{err.Error()}
+	var messages interface{ Messages() []string }
+	if errors.As(err, &messages) {
+		validationErrors = messages.Messages()
+	}
+
+	p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+	WriteProblem(w, p.WithExtension("errors", validationErrors))
+	return true
+}
+
+func NilMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi"
+
+	"github.com/cugu/swagger-go-chi/testdata/formats/generated/model"
+)
+
+type Service interface {
+	CreateDevice(context.Context, *model.Device) (*model.Device, error)
+	UploadFirmware(context.Context, string, io.Reader) error
+}
+
+// securitySchemes are the securityDefinitions of the spec.
+var securitySchemes = map[string]SecurityScheme{}
+
+// NewServer returns the router of the API. Requests are authenticated by the
+// authenticate middleware, if it is set, and the security requirements of
+// the operations are checked by the authorizer. Public operations, which
+// have an empty list of security requirements, skip both. The errors of the
+// service are mapped to problems by the errorMapper, if it is set, and by
+// MapErrors.
+func NewServer(service Service, authenticate func(http.Handler) http.Handler, authorizer Authorizer, errorMapper ErrorMapper, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	if authenticate == nil {
+		authenticate = NilMiddleware()
+	}
+
+	s := &server{service, errorMapper}
+
+	r.With(authenticate).Post("/devices", s.createDeviceHandler)
+	r.With(authenticate).Put("/devices/{id}/firmware", s.uploadFirmwareHandler)
+	return r
+}
+
+// ValidateResponses returns a middleware that checks the responses of the
+// operations against the spec. Violations are logged, if fail is set the
+// response is replaced by a problem with the status 500.
+func ValidateResponses(fail bool) func(http.Handler) http.Handler {
+	return validateResponses(operationSpecs, fail)
+}
+
+// operationSpecs are the declared responses of the operations by method and
+// route pattern.
+var operationSpecs = map[string]*operationSpec{
+	"POST /devices": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"200": {body: func() interface{} { return new(*model.Device) }},
+		},
+	},
+	"PUT /devices/{id}/firmware": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+}
+
+type server struct {
+	service     Service
+	errorMapper ErrorMapper
+}
+
+// serviceError maps an error of the service to a problem.
+func (s *server) serviceError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s.errorMapper != nil {
+		if p := s.errorMapper(err); p != nil {
+			return p
+		}
+	}
+	if p := MapErrors(err); p != nil {
+		return p
+	}
+	return err
+}
+
+// MapErrors maps the sentinel errors and error types of the model package
+// to problems, other errors are mapped to nil.
+func MapErrors(err error) *Problem {
+	var validationErrors model.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+		return p.WithExtension("errors", validationErrors.Messages())
+	}
+
+	var status int
+	switch {
+	case errors.Is(err, model.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, model.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, model.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, model.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, model.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	default:
+		return nil
+	}
+
+	p := NewProblem(status, "", err.Error()).WithCause(err)
+	var coded *model.CodedError
+	if errors.As(err, &coded) {
+		p.Code = coded.Code
+	}
+	return p
+}
+
+func (s *server) createDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+
+	var deviceP *model.Device
+	if err := decodeBody(r, []string{"application/json"}, &deviceP); err != nil {
+		bodyError(w, err)
+		return
+	}
+
+	if validateBody(w, deviceP) {
+		return
+	}
+
+	result, err := s.service.CreateDevice(r.Context(), deviceP)
+	response(w, enc, result, s.serviceError(err))
+}
+
+func (s *server) uploadFirmwareHandler(w http.ResponseWriter, r *http.Request) {
+	idP := chi.URLParam(r, "id")
+
+	if _, err := requestMediaType(r, []string{"application/octet-stream"}); err != nil {
+		bodyError(w, err)
+		return
+	}
+	firmwareP := r.Body
+
+	v := &model.Validator{}
+	v.Format("id", idP, "uuid")
+
+	if validationError(w, v.Err()) {
+		return
+	}
+
+	response(w, nil, nil, s.serviceError(s.service.UploadFirmware(r.Context(), idP, firmwareP)))
+}
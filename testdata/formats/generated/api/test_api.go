@@ -0,0 +1,36 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "CreateDevice",
+		Args: Args{Method: "Post", URL: "/devices", Data: map[string]interface{}{"id": "4c0a5f5e-3d1b-4a8e-9d8b-5f0c2a1e7b3d", "ip": "10.0.0.1"}},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "UploadFirmware",
+		Args: Args{Method: "Put", URL: "/devices/4c0a5f5e-3d1b-4a8e-9d8b-5f0c2a1e7b3d/firmware"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+}
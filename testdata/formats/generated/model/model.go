@@ -0,0 +1,57 @@
+package model
+
+import (
+	"net"
+	"time"
+)
+
+type Device struct {
+	Color       *string    `json:"color,omitempty" xml:"color,omitempty"`
+	Homepage    *string    `json:"homepage,omitempty" xml:"homepage,omitempty"`
+	ID          string     `json:"id" xml:"id"`
+	Installed   *Date      `json:"installed,omitempty" xml:"installed,omitempty"`
+	Ip          net.IP     `json:"ip" xml:"ip"`
+	Ip6         net.IP     `json:"ip6,omitempty" xml:"ip6,omitempty"`
+	Key         []byte     `json:"key,omitempty" xml:"key,omitempty"`
+	Load        *float32   `json:"load,omitempty" xml:"load,omitempty"`
+	Owner       *string    `json:"owner,omitempty" xml:"owner,omitempty"`
+	Password    *string    `json:"password,omitempty" xml:"password,omitempty"`
+	Port        *int32     `json:"port,omitempty" xml:"port,omitempty"`
+	Rssi        *int       `json:"rssi,omitempty" xml:"rssi,omitempty"`
+	Serial      *int64     `json:"serial,omitempty" xml:"serial,omitempty"`
+	Temperature *float64   `json:"temperature,omitempty" xml:"temperature,omitempty"`
+	Updated     *time.Time `json:"updated,omitempty" xml:"updated,omitempty"`
+	Weight      *float64   `json:"weight,omitempty" xml:"weight,omitempty"`
+}
+
+// Validate returns an error if m does not match the Device schema.
+func (m *Device) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Device) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Homepage != nil {
+		v.Format(field(path, "homepage"), *m.Homepage, "uri")
+	}
+	v.Format(field(path, "id"), m.ID, "uuid")
+	if m.Ip == nil {
+		v.Required(field(path, "ip"))
+	} else {
+		v.IPv4(field(path, "ip"), m.Ip)
+	}
+	if m.Ip6 != nil {
+		v.IPv6(field(path, "ip6"), m.Ip6)
+	}
+	if m.Owner != nil {
+		v.Format(field(path, "owner"), *m.Owner, "email")
+	}
+	if m.Port != nil {
+		v.Maximum(field(path, "port"), float64(*m.Port), 65535)
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/cugu/swagger-go-chi/testdata/headers/generated/model"
+)
+
+type Service interface {
+	ListReports(context.Context, string, *int32, *model.ListReportsXMode, []string, string, *bool, *time.Time) (ListReportsResponse, error)
+}
+
+// securitySchemes are the securityDefinitions of the spec.
+var securitySchemes = map[string]SecurityScheme{}
+
+// NewServer returns the router of the API. Requests are authenticated by the
+// authenticate middleware, if it is set, and the security requirements of
+// the operations are checked by the authorizer. Public operations, which
+// have an empty list of security requirements, skip both. The errors of the
+// service are mapped to problems by the errorMapper, if it is set, and by
+// MapErrors.
+func NewServer(service Service, authenticate func(http.Handler) http.Handler, authorizer Authorizer, errorMapper ErrorMapper, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	if authenticate == nil {
+		authenticate = NilMiddleware()
+	}
+
+	s := &server{service, errorMapper}
+
+	r.With(authenticate).Get("/reports", s.listReportsHandler)
+	return r
+}
+
+// ValidateResponses returns a middleware that checks the responses of the
+// operations against the spec. Violations are logged, if fail is set the
+// response is replaced by a problem with the status 500.
+func ValidateResponses(fail bool) func(http.Handler) http.Handler {
+	return validateResponses(operationSpecs, fail)
+}
+
+// operationSpecs are the declared responses of the operations by method and
+// route pattern.
+var operationSpecs = map[string]*operationSpec{
+	"GET /reports": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"200": {body: func() interface{} { return new([]string) }},
+		},
+	},
+}
+
+type server struct {
+	service     Service
+	errorMapper ErrorMapper
+}
+
+// serviceError maps an error of the service to a problem.
+func (s *server) serviceError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s.errorMapper != nil {
+		if p := s.errorMapper(err); p != nil {
+			return p
+		}
+	}
+	if p := MapErrors(err); p != nil {
+		return p
+	}
+	return err
+}
+
+// MapErrors maps the sentinel errors and error types of the model package
+// to problems, other errors are mapped to nil.
+func MapErrors(err error) *Problem {
+	var validationErrors model.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+		return p.WithExtension("errors", validationErrors.Messages())
+	}
+
+	var status int
+	switch {
+	case errors.Is(err, model.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, model.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, model.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, model.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, model.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	default:
+		return nil
+	}
+
+	p := NewProblem(status, "", err.Error()).WithCause(err)
+	var coded *model.CodedError
+	if errors.As(err, &coded) {
+		p.Code = coded.Code
+	}
+	return p
+}
+
+func (s *server) listReportsHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	xRequestIdP, err := parseHeaderValue(r, "X-Request-ID", parseString)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	xPageSizeP, err := parseHeaderOptionalValue(r, "X-Page-Size", parseInt32)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	xModeP, err := parseHeaderOptionalValue(r, "X-Mode", model.ParseListReportsXMode)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	xTagsP, err := parseHeaderValueArray(r, "X-Tags", parseString)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	sessionP, err := parseCookieValue(r, "session", parseString)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	debugP, err := parseCookieOptionalValue(r, "debug", parseBool)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	sinceP, err := parseCookieOptionalValue(r, "since", parseDateTime)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	v := &model.Validator{}
+	v.Format("X-Request-ID", xRequestIdP, "uuid")
+	if xPageSizeP != nil {
+		v.Minimum("X-Page-Size", float64(*xPageSizeP), 1)
+		v.Maximum("X-Page-Size", float64(*xPageSizeP), 100)
+	}
+	if xTagsP != nil {
+		v.MaxItems("X-Tags", len(xTagsP), 5)
+	}
+	v.MinLength("session", sessionP, 8)
+
+	if validationError(w, v.Err()) {
+		return
+	}
+
+	result, err := s.service.ListReports(r.Context(), xRequestIdP, xPageSizeP, xModeP, xTagsP, sessionP, debugP, sinceP)
+	respond(w, r, enc, result, s.serviceError(err))
+}
+
+// ListReportsResponse is one of the responses of the listReports operation.
+type ListReportsResponse interface {
+	responder
+	isListReportsResponse()
+}
+
+// ListReports200Response is the 200 response of the listReports operation.
+type ListReports200Response struct {
+	Body        []string
+	Expires     *time.Time
+	XModes      []string
+	XRequestId  string
+	XTotalCount int
+}
+
+// ListReportsResponse200 returns the 200 response of the listReports operation.
+func ListReportsResponse200(body []string) *ListReports200Response {
+	return &ListReports200Response{Body: body}
+}
+
+func (*ListReports200Response) isListReportsResponse() {}
+
+func (r *ListReports200Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	if r.Expires != nil {
+		w.Header().Set("Expires", formatValue(*r.Expires))
+	}
+	if len(r.XModes) > 0 {
+		w.Header().Set("X-Modes", formatValues(r.XModes, ","))
+	}
+	if r.XRequestId != "" {
+		w.Header().Set("X-Request-ID", r.XRequestId)
+	}
+	w.Header().Set("X-Total-Count", formatValue(r.XTotalCount))
+	e.write(w, 200, r.Body)
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type ListReportsXMode string
+
+const (
+	ListReportsXModeFull    ListReportsXMode = "full"
+	ListReportsXModeSummary ListReportsXMode = "summary"
+)
+
+// AllListReportsXMode returns all valid ListReportsXMode values.
+func AllListReportsXMode() []ListReportsXMode {
+	return []ListReportsXMode{ListReportsXModeFull, ListReportsXModeSummary}
+}
+
+// Valid returns true if v is one of the ListReportsXMode values.
+func (v ListReportsXMode) Valid() bool {
+	switch v {
+	case ListReportsXModeFull, ListReportsXModeSummary:
+		return true
+	}
+	return false
+}
+
+func (v *ListReportsXMode) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !ListReportsXMode(value).Valid() {
+		return fmt.Errorf("invalid ListReportsXMode %v", value)
+	}
+	*v = ListReportsXMode(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the ListReportsXMode values.
+func (m ListReportsXMode) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m ListReportsXMode) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllListReportsXMode())
+	}
+}
+
+// ParseListReportsXMode parses a ListReportsXMode from its string representation.
+func ParseListReportsXMode(s string) (ListReportsXMode, error) {
+	v := ListReportsXMode(s)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid ListReportsXMode %q", s)
+	}
+	return v, nil
+}
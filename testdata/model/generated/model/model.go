@@ -1,37 +1,19 @@
 package model
 
-import (
-	"time"
-
-	"github.com/xeipuuv/gojsonschema"
-)
-
-var (
-	schemaLoader = gojsonschema.NewSchemaLoader()
-	UserSchema   = new(gojsonschema.Schema)
-)
-
-func init() {
-	err := schemaLoader.AddSchemas(
-		gojsonschema.NewStringLoader(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"],"$id":"#/definitions/User"}`),
-	)
-	if err != nil {
-		panic(err)
-	}
-
-	UserSchema = mustCompile(`#/definitions/User`)
+type User struct {
+	Name string `json:"name" xml:"name"`
 }
 
-type User struct {
-	Name string `json:"name"`
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
 }
 
-func mustCompile(uri string) *gojsonschema.Schema {
-	s, err := schemaLoader.Compile(gojsonschema.NewReferenceLoader(uri))
-	if err != nil {
-		panic(err)
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
 	}
-	return s
 }
-
-const ()
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+)
+
+// Debug exposes the details of unknown errors in problem responses, which
+// are hidden otherwise.
+var Debug = false
+
+// Problem is an error response as defined by RFC 7807. Problems can be
+// returned by services to respond with a specific status, the extension
+// members are written next to the standard members.
+type Problem struct {
+	Type       string                 `json:"type,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Status     int                    `json:"status,omitempty"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Code       string                 `json:"code,omitempty"` // machine-readable error code
+	Extensions map[string]interface{} `json:"-"`
+
+	cause error
+}
+
+// NewProblem returns a problem with the status, a machine-readable code and
+// a human-readable detail. The title is the text of the status.
+func NewProblem(status int, code, detail string) *Problem {
+	return &Problem{Title: http.StatusText(status), Status: status, Code: code, Detail: detail}
+}
+
+// BadRequest returns a problem with the status 400.
+func BadRequest(code, detail string) *Problem {
+	return NewProblem(http.StatusBadRequest, code, detail)
+}
+
+// Unauthorized returns a problem with the status 401.
+func Unauthorized(code, detail string) *Problem {
+	return NewProblem(http.StatusUnauthorized, code, detail)
+}
+
+// Forbidden returns a problem with the status 403.
+func Forbidden(code, detail string) *Problem {
+	return NewProblem(http.StatusForbidden, code, detail)
+}
+
+// NotFound returns a problem with the status 404.
+func NotFound(code, detail string) *Problem {
+	return NewProblem(http.StatusNotFound, code, detail)
+}
+
+// Conflict returns a problem with the status 409.
+func Conflict(code, detail string) *Problem {
+	return NewProblem(http.StatusConflict, code, detail)
+}
+
+// WithType sets the URI that identifies the problem type.
+func (p *Problem) WithType(uri string) *Problem {
+	p.Type = uri
+	return p
+}
+
+// WithInstance sets the URI that identifies the occurrence of the problem.
+func (p *Problem) WithInstance(uri string) *Problem {
+	p.Instance = uri
+	return p
+}
+
+// WithExtension adds an extension member to the problem.
+func (p *Problem) WithExtension(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = map[string]interface{}{}
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+// WithCause sets the error that caused the problem, which is not written to
+// the response.
+func (p *Problem) WithCause(err error) *Problem {
+	p.cause = err
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	if p.Title == "" {
+		return p.Detail
+	}
+	return p.Title + ": " + p.Detail
+}
+
+func (p *Problem) Unwrap() error {
+	return p.cause
+}
+
+// problemMembers are the JSON names of the fields of Problem.
+var problemMembers = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true, "code": true}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	b, err := json.Marshal((*plain)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return b, err
+	}
+
+	keys := make([]string, 0, len(p.Extensions))
+	for key := range p.Extensions {
+		// the standard members take precedence over extensions of the same name
+		if !problemMembers[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(b[:len(b)-1])
+	for i, key := range keys {
+		value, err := json.Marshal(p.Extensions[key])
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 || len(b) > 2 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	type plain Problem
+	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
+		return err
+	}
+
+	var members map[string]interface{}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+	for key := range problemMembers {
+		delete(members, key)
+	}
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
+
+// ErrorMapper maps an error returned by a service to a problem. Errors that
+// are mapped to nil are written with the default mapping.
+type ErrorMapper func(err error) *Problem
+
+// problem returns the problem of an error. HTTP errors keep their status,
+// unknown errors are internal server errors without details unless Debug
+// is set.
+func problem(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+
+	var httpError *HTTPError
+	if errors.As(err, &httpError) {
+		detail := ""
+		if httpError.Internal != nil {
+			detail = httpError.Internal.Error()
+		}
+		return NewProblem(httpError.Status, "", detail).WithCause(err)
+	}
+
+	p = NewProblem(http.StatusInternalServerError, "", "").WithCause(err)
+	if Debug {
+		p.Detail = err.Error()
+	}
+	return p
+}
+
+// WriteError writes the problem of an error. Unknown errors are written
+// without details unless Debug is set.
+func WriteError(w http.ResponseWriter, err error) {
+	WriteProblem(w, problem(err))
+}
+
+// WriteProblem writes a problem as application/problem+json.
+func WriteProblem(w http.ResponseWriter, p *Problem) {
+	status := p.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	b, _ := json.Marshal(p)
+	w.Write(b)
+}
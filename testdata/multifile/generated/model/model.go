@@ -0,0 +1,95 @@
+package model
+
+type Address struct {
+	Geo    *AddressGeo `json:"geo,omitempty" xml:"geo,omitempty"`
+	Street *string     `json:"street,omitempty" xml:"street,omitempty"`
+}
+
+// Validate returns an error if m does not match the Address schema.
+func (m *Address) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Address) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Geo != nil {
+		m.Geo.validate(v, field(path, "geo"))
+	}
+}
+
+type AddressGeo struct {
+	Lat *float64 `json:"lat,omitempty" xml:"lat,omitempty"`
+	Lon *float64 `json:"lon,omitempty" xml:"lon,omitempty"`
+}
+
+// Validate returns an error if m does not match the AddressGeo schema.
+func (m *AddressGeo) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *AddressGeo) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type Geo struct {
+	Lat *float64 `json:"lat,omitempty" xml:"lat,omitempty"`
+	Lon *float64 `json:"lon,omitempty" xml:"lon,omitempty"`
+}
+
+// Validate returns an error if m does not match the Geo schema.
+func (m *Geo) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Geo) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type User struct {
+	Address  *Address `json:"address,omitempty" xml:"address,omitempty"`
+	ID       *string  `json:"id,omitempty" xml:"id,omitempty"`
+	Location *Geo     `json:"location,omitempty" xml:"location,omitempty"`
+	Manager  *User    `json:"manager,omitempty" xml:"manager,omitempty"`
+	Name     string   `json:"name" xml:"name"`
+}
+
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Address != nil {
+		m.Address.validate(v, field(path, "address"))
+	}
+	if m.ID != nil {
+		v.Format(field(path, "id"), *m.ID, "uuid")
+	}
+	if m.Location != nil {
+		m.Location.validate(v, field(path, "location"))
+	}
+	if m.Manager != nil {
+		m.Manager.validate(v, field(path, "manager"))
+	}
+}
@@ -0,0 +1,27 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "CreateUser",
+		Args: Args{Method: "Post", URL: "/users", Data: map[string]interface{}{"name": "bob"}},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+}
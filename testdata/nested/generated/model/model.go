@@ -0,0 +1,153 @@
+package model
+
+type CreateUserBody struct {
+	Name string `json:"name" xml:"name"`
+}
+
+// Validate returns an error if m does not match the CreateUserBody schema.
+func (m *CreateUserBody) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *CreateUserBody) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type CreateUserResult struct {
+	ID   *string `json:"id,omitempty" xml:"id,omitempty"`
+	User *User   `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+// Validate returns an error if m does not match the CreateUserResult schema.
+func (m *CreateUserResult) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *CreateUserResult) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.User != nil {
+		m.User.validate(v, field(path, "user"))
+	}
+}
+
+type User struct {
+	Address  *UserAddress                `json:"address,omitempty" xml:"address,omitempty"`
+	Labels   map[string]*UserLabelsValue `json:"labels,omitempty" xml:"labels,omitempty"`
+	Metadata map[string]interface{}      `json:"metadata,omitempty" xml:"metadata,omitempty"`
+	Name     string                      `json:"name" xml:"name"`
+	Tags     []*UserTagsItem             `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Address != nil {
+		m.Address.validate(v, field(path, "address"))
+	}
+	for key0, item0 := range m.Labels {
+		if item0 != nil {
+			item0.validate(v, field(field(path, "labels"), key0))
+		}
+	}
+	for i0, item0 := range m.Tags {
+		if item0 != nil {
+			item0.validate(v, index(field(path, "tags"), i0))
+		}
+	}
+}
+
+type UserAddress struct {
+	Geo    *UserAddressGeo `json:"geo,omitempty" xml:"geo,omitempty"`
+	Street string          `json:"street" xml:"street"`
+}
+
+// Validate returns an error if m does not match the UserAddress schema.
+func (m *UserAddress) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *UserAddress) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Geo != nil {
+		m.Geo.validate(v, field(path, "geo"))
+	}
+}
+
+type UserAddressGeo struct {
+	Lat *float64 `json:"lat,omitempty" xml:"lat,omitempty"`
+	Lon *float64 `json:"lon,omitempty" xml:"lon,omitempty"`
+}
+
+// Validate returns an error if m does not match the UserAddressGeo schema.
+func (m *UserAddressGeo) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *UserAddressGeo) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type UserLabelsValue struct {
+	Value *string `json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// Validate returns an error if m does not match the UserLabelsValue schema.
+func (m *UserLabelsValue) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *UserLabelsValue) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type UserTagsItem struct {
+	Name *string `json:"name,omitempty" xml:"name,omitempty"`
+}
+
+// Validate returns an error if m does not match the UserTagsItem schema.
+func (m *UserTagsItem) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *UserTagsItem) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "UploadFile",
+		Args: Args{Method: "Put", URL: "/file"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "ListUsers",
+		Args: Args{Method: "Get", URL: "/users?offset=2"},
+		Want: Want{
+			Status: 200,
+			Body:   []interface{}{map[string]interface{}{"name": "bob"}},
+		},
+	},
+
+	{
+		Name: "CreateUser",
+		Args: Args{Method: "Post", URL: "/users", Data: map[string]interface{}{"name": "bob"}},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "GetUser",
+		Args: Args{Method: "Get", URL: "/users/bob"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "DeleteUser",
+		Args: Args{Method: "Delete", URL: "/users/bob"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+}
@@ -0,0 +1,41 @@
+package model
+
+type Address struct {
+	Street *string `json:"street,omitempty" xml:"street,omitempty"`
+}
+
+// Validate returns an error if m does not match the Address schema.
+func (m *Address) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Address) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type User struct {
+	Address *Address `json:"address,omitempty" xml:"address,omitempty"`
+	Name    string   `json:"name" xml:"name"`
+}
+
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Address != nil {
+		m.Address.validate(v, field(path, "address"))
+	}
+}
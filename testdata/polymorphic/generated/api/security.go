@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+)
+
+// SecurityRequirement maps the names of security schemes to the required
+// scopes. A request has to satisfy all schemes of one of the requirements of
+// an operation, an empty requirement allows anonymous requests.
+type SecurityRequirement map[string][]string
+
+// SecurityScheme is a security scheme of the securityDefinitions of the
+// spec. Schemes without definition, like the legacy roles scheme, only have
+// a name.
+type SecurityScheme struct {
+	Name      string // the name of the scheme in the securityDefinitions
+	Type      string // basic, apiKey or oauth2
+	In        string // header or query of apiKey schemes
+	Parameter string // the header or query parameter of apiKey schemes
+	Scheme    string // the scheme of the Authorization header, like bearer
+}
+
+// Authorizer checks if a request satisfies the security scheme with the
+// required scopes. Authorizers must reject schemes they cannot check.
+// Problems keep their status, other errors are written with the status 401.
+type Authorizer func(r *http.Request, scheme SecurityScheme, scopes []string) error
+
+// IgnoreSecurity is an Authorizer that allows all requests.
+func IgnoreSecurity(*http.Request, SecurityScheme, []string) error {
+	return nil
+}
+
+// authorize returns a middleware that checks the security requirements of an
+// operation with the authorizer, the schemes are looked up by their name. If
+// no requirement is satisfied, the error with the highest status is written,
+// so users that are authenticated but not authorized get a 403 response.
+func authorize(authorizer Authorizer, schemes map[string]SecurityScheme, reqs []SecurityRequirement) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if authorizer == nil {
+				WriteProblem(w, Unauthorized("no_authorizer", "the server has no authorizer"))
+				return
+			}
+
+			var denied *Problem
+			for _, req := range reqs {
+				p := checkRequirement(authorizer, schemes, r, req)
+				if p == nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+				if denied == nil || p.Status > denied.Status {
+					denied = p
+				}
+			}
+			WriteProblem(w, denied)
+		})
+	}
+}
+
+// checkRequirement returns the problem of the first scheme of a security
+// requirement the request does not satisfy.
+func checkRequirement(authorizer Authorizer, schemes map[string]SecurityScheme, r *http.Request, req SecurityRequirement) *Problem {
+	names := make([]string, 0, len(req))
+	for name := range req {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scheme, ok := schemes[name]
+		if !ok {
+			scheme = SecurityScheme{Name: name}
+		}
+		err := authorizer(r, scheme, req[name])
+		if err == nil {
+			continue
+		}
+		var p *Problem
+		if !errors.As(err, &p) {
+			p = Unauthorized("", err.Error()).WithCause(err)
+		}
+		return p
+	}
+	return nil
+}
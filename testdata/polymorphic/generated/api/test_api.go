@@ -0,0 +1,45 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "CreatePet",
+		Args: Args{Method: "Post", URL: "/pets", Data: map[string]interface{}{"lives": 9, "name": "tom", "petType": "Cat"}},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "CreatePets",
+		Args: Args{Method: "Post", URL: "/pets/batch", Data: []interface{}{map[string]interface{}{"bark": true, "name": "rex", "petType": "dog"}}},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "CreateDrawing",
+		Args: Args{Method: "Post", URL: "/shapes", Data: map[string]interface{}{"shapes": []interface{}{map[string]interface{}{"kind": "circle", "radius": 1}}}},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+}
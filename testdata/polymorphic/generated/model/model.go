@@ -0,0 +1,305 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Cat struct {
+	PetBase
+	Lives *int `json:"lives,omitempty" xml:"lives,omitempty"`
+}
+
+// Validate returns an error if m does not match the Cat schema.
+func (m *Cat) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Cat) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	m.PetBase.validate(v, path)
+}
+
+type Circle struct {
+	Kind   string  `json:"kind" xml:"kind"`
+	Radius float64 `json:"radius" xml:"radius"`
+}
+
+// Validate returns an error if m does not match the Circle schema.
+func (m *Circle) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Circle) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type Dog struct {
+	PetBase
+	Bark *bool `json:"bark,omitempty" xml:"bark,omitempty"`
+}
+
+// Validate returns an error if m does not match the Dog schema.
+func (m *Dog) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Dog) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	m.PetBase.validate(v, path)
+}
+
+type Drawing struct {
+	Background *ShapeValue   `json:"background,omitempty" xml:"background,omitempty"`
+	Label      DrawingLabel  `json:"label,omitempty" xml:"label,omitempty"`
+	Shapes     []*ShapeValue `json:"shapes" xml:"shapes"`
+}
+
+// Validate returns an error if m does not match the Drawing schema.
+func (m *Drawing) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Drawing) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Background != nil {
+		m.Background.validate(v, field(path, "background"))
+	}
+	if m.Shapes == nil {
+		v.Required(field(path, "shapes"))
+	}
+	for i0, item0 := range m.Shapes {
+		if item0 != nil {
+			item0.validate(v, index(field(path, "shapes"), i0))
+		}
+	}
+}
+
+// DrawingLabel is kept as raw JSON, as it has no discriminator.
+type DrawingLabel = json.RawMessage
+
+// Pet is one of the types of the petType mapping.
+type Pet interface {
+	isPet()
+	validate(v *Validator, path string)
+}
+
+func (*Cat) isPet() {}
+
+// MarshalJSON writes Cat with the petType "Cat".
+func (m Cat) MarshalJSON() ([]byte, error) {
+	type plain Cat
+	m.PetType = "Cat"
+	return json.Marshal(plain(m))
+}
+
+func (*Dog) isPet() {}
+
+// MarshalJSON writes Dog with the petType "dog".
+func (m Dog) MarshalJSON() ([]byte, error) {
+	type plain Dog
+	m.PetType = "dog"
+	return json.Marshal(plain(m))
+}
+
+// UnmarshalPet decodes JSON into the type selected by the petType property.
+func UnmarshalPet(b []byte) (Pet, error) {
+	var discriminator struct {
+		Value string `json:"petType"`
+	}
+	if err := json.Unmarshal(b, &discriminator); err != nil {
+		return nil, err
+	}
+
+	var v Pet
+	switch discriminator.Value {
+	case "Cat":
+		v = &Cat{}
+	case "dog":
+		v = &Dog{}
+	default:
+		return nil, fmt.Errorf("unknown petType %q", discriminator.Value)
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// PetValue wraps a Pet so it can be decoded from JSON.
+type PetValue struct {
+	Pet
+}
+
+func (v *PetValue) UnmarshalJSON(b []byte) error {
+	value, err := UnmarshalPet(b)
+	if err != nil {
+		return err
+	}
+	v.Pet = value
+	return nil
+}
+
+func (v PetValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Pet)
+}
+
+// Validate returns an error if the value does not match the Pet schema.
+func (m *PetValue) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *PetValue) validate(v *Validator, path string) {
+	if m == nil || m.Pet == nil {
+		v.Required(path)
+		return
+	}
+	m.Pet.validate(v, path)
+}
+
+type PetBase struct {
+	Name    string `json:"name" xml:"name"`
+	PetType string `json:"petType" xml:"petType"`
+}
+
+// Validate returns an error if m does not match the PetBase schema.
+func (m *PetBase) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *PetBase) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+// Shape is one of the types of the kind mapping.
+type Shape interface {
+	isShape()
+	validate(v *Validator, path string)
+}
+
+func (*Circle) isShape() {}
+
+// MarshalJSON writes Circle with the kind "circle".
+func (m Circle) MarshalJSON() ([]byte, error) {
+	type plain Circle
+	m.Kind = "circle"
+	return json.Marshal(plain(m))
+}
+
+func (*Square) isShape() {}
+
+// MarshalJSON writes Square with its kind, which is set to "box" if it is not one of the values of Square.
+func (m Square) MarshalJSON() ([]byte, error) {
+	type plain Square
+	switch m.Kind {
+	case "box", "square":
+	default:
+		m.Kind = "box"
+	}
+	return json.Marshal(plain(m))
+}
+
+// UnmarshalShape decodes JSON into the type selected by the kind property.
+func UnmarshalShape(b []byte) (Shape, error) {
+	var discriminator struct {
+		Value string `json:"kind"`
+	}
+	if err := json.Unmarshal(b, &discriminator); err != nil {
+		return nil, err
+	}
+
+	var v Shape
+	switch discriminator.Value {
+	case "circle":
+		v = &Circle{}
+	case "box", "square":
+		v = &Square{}
+	default:
+		return nil, fmt.Errorf("unknown kind %q", discriminator.Value)
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ShapeValue wraps a Shape so it can be decoded from JSON.
+type ShapeValue struct {
+	Shape
+}
+
+func (v *ShapeValue) UnmarshalJSON(b []byte) error {
+	value, err := UnmarshalShape(b)
+	if err != nil {
+		return err
+	}
+	v.Shape = value
+	return nil
+}
+
+func (v ShapeValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Shape)
+}
+
+// Validate returns an error if the value does not match the Shape schema.
+func (m *ShapeValue) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *ShapeValue) validate(v *Validator, path string) {
+	if m == nil || m.Shape == nil {
+		v.Required(path)
+		return
+	}
+	m.Shape.validate(v, path)
+}
+
+type Square struct {
+	Kind string  `json:"kind" xml:"kind"`
+	Side float64 `json:"side" xml:"side"`
+}
+
+// Validate returns an error if m does not match the Square schema.
+func (m *Square) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Square) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/cugu/swagger-go-chi/testdata/query/generated/model"
+)
+
+type Service interface {
+	ListEvents(context.Context, int32, time.Time, *model.Date, *int64, *float32, *bool, []int, []model.ListEventsLevelsItem, []string, []float64, []model.Date) ([]string, error)
+}
+
+// securitySchemes are the securityDefinitions of the spec.
+var securitySchemes = map[string]SecurityScheme{}
+
+// NewServer returns the router of the API. Requests are authenticated by the
+// authenticate middleware, if it is set, and the security requirements of
+// the operations are checked by the authorizer. Public operations, which
+// have an empty list of security requirements, skip both. The errors of the
+// service are mapped to problems by the errorMapper, if it is set, and by
+// MapErrors.
+func NewServer(service Service, authenticate func(http.Handler) http.Handler, authorizer Authorizer, errorMapper ErrorMapper, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	if authenticate == nil {
+		authenticate = NilMiddleware()
+	}
+
+	s := &server{service, errorMapper}
+
+	r.With(authenticate).Get("/events/{year}", s.listEventsHandler)
+	return r
+}
+
+// ValidateResponses returns a middleware that checks the responses of the
+// operations against the spec. Violations are logged, if fail is set the
+// response is replaced by a problem with the status 500.
+func ValidateResponses(fail bool) func(http.Handler) http.Handler {
+	return validateResponses(operationSpecs, fail)
+}
+
+// operationSpecs are the declared responses of the operations by method and
+// route pattern.
+var operationSpecs = map[string]*operationSpec{
+	"GET /events/{year}": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"200": {body: func() interface{} { return new([]string) }},
+		},
+	},
+}
+
+type server struct {
+	service     Service
+	errorMapper ErrorMapper
+}
+
+// serviceError maps an error of the service to a problem.
+func (s *server) serviceError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s.errorMapper != nil {
+		if p := s.errorMapper(err); p != nil {
+			return p
+		}
+	}
+	if p := MapErrors(err); p != nil {
+		return p
+	}
+	return err
+}
+
+// MapErrors maps the sentinel errors and error types of the model package
+// to problems, other errors are mapped to nil.
+func MapErrors(err error) *Problem {
+	var validationErrors model.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+		return p.WithExtension("errors", validationErrors.Messages())
+	}
+
+	var status int
+	switch {
+	case errors.Is(err, model.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, model.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, model.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, model.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, model.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	default:
+		return nil
+	}
+
+	p := NewProblem(status, "", err.Error()).WithCause(err)
+	var coded *model.CodedError
+	if errors.As(err, &coded) {
+		p.Code = coded.Code
+	}
+	return p
+}
+
+func (s *server) listEventsHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	yearP, err := parseURLValue(r, "year", parseInt32)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	afterP, err := parseQueryValue(r, "after", parseDateTime)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	beforeP, err := parseQueryOptionalValue(r, "before", model.ParseDate)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	cursorP, err := parseQueryOptionalValue(r, "cursor", parseInt64)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	scoreP, err := parseQueryOptionalValue(r, "score", parseFloat32)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	archivedP, err := parseQueryOptionalValue(r, "archived", parseBool)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	idsP, err := parseQueryValueArray(r, "ids", "", 1000, parseInt)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	levelsP, err := parseQueryValueArray(r, "levels", "|", 3, model.ParseListEventsLevelsItem)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	wordsP, err := parseQueryValueArray(r, "words", " ", 1000, parseString)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	weightsP, err := parseQueryValueArray(r, "weights", "\t", 1000, parseFloat64)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	daysP, err := parseQueryValueArray(r, "days", ",", 1000, model.ParseDate)
+	if err != nil {
+		JSONError(w, err)
+		return
+	}
+
+	v := &model.Validator{}
+	if levelsP != nil {
+		v.MaxItems("levels", len(levelsP), 3)
+	}
+
+	if validationError(w, v.Err()) {
+		return
+	}
+
+	result, err := s.service.ListEvents(r.Context(), yearP, afterP, beforeP, cursorP, scoreP, archivedP, idsP, levelsP, wordsP, weightsP, daysP)
+	response(w, enc, result, s.serviceError(err))
+}
@@ -0,0 +1,27 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "ListEvents",
+		Args: Args{Method: "Get", URL: "/events/2024?after=2024-01-01T00%3A00%3A00Z"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+}
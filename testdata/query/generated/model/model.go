@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type ListEventsLevelsItem string
+
+const (
+	ListEventsLevelsItemDebug ListEventsLevelsItem = "debug"
+	ListEventsLevelsItemInfo  ListEventsLevelsItem = "info"
+	ListEventsLevelsItemError ListEventsLevelsItem = "error"
+)
+
+// AllListEventsLevelsItem returns all valid ListEventsLevelsItem values.
+func AllListEventsLevelsItem() []ListEventsLevelsItem {
+	return []ListEventsLevelsItem{ListEventsLevelsItemDebug, ListEventsLevelsItemInfo, ListEventsLevelsItemError}
+}
+
+// Valid returns true if v is one of the ListEventsLevelsItem values.
+func (v ListEventsLevelsItem) Valid() bool {
+	switch v {
+	case ListEventsLevelsItemDebug, ListEventsLevelsItemInfo, ListEventsLevelsItemError:
+		return true
+	}
+	return false
+}
+
+func (v *ListEventsLevelsItem) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	if !ListEventsLevelsItem(value).Valid() {
+		return fmt.Errorf("invalid ListEventsLevelsItem %v", value)
+	}
+	*v = ListEventsLevelsItem(value)
+	return nil
+}
+
+// Validate returns an error if m is not one of the ListEventsLevelsItem values.
+func (m ListEventsLevelsItem) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m ListEventsLevelsItem) validate(v *Validator, path string) {
+	if !m.Valid() {
+		v.Add(path, "must be one of %v", AllListEventsLevelsItem())
+	}
+}
+
+// ParseListEventsLevelsItem parses a ListEventsLevelsItem from its string representation.
+func ParseListEventsLevelsItem(s string) (ListEventsLevelsItem, error) {
+	v := ListEventsLevelsItem(s)
+	if !v.Valid() {
+		return v, fmt.Errorf("invalid ListEventsLevelsItem %q", s)
+	}
+	return v, nil
+}
@@ -0,0 +1,427 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+
+	"github.com/cugu/swagger-go-chi/testdata/responses/generated/model"
+)
+
+type Service interface {
+	StartJob(context.Context) (StartJobResponse, error)
+	Ping(context.Context) error
+	CreateUser(context.Context, *model.User) (CreateUserResponse, error)
+	CountUsers(context.Context) (CountUsersResponse, error)
+	GetUser(context.Context, string) (GetUserResponse, error)
+	DeleteUser(context.Context, string) (DeleteUserResponse, error)
+}
+
+// securitySchemes are the securityDefinitions of the spec.
+var securitySchemes = map[string]SecurityScheme{}
+
+// NewServer returns the router of the API. Requests are authenticated by the
+// authenticate middleware, if it is set, and the security requirements of
+// the operations are checked by the authorizer. Public operations, which
+// have an empty list of security requirements, skip both. The errors of the
+// service are mapped to problems by the errorMapper, if it is set, and by
+// MapErrors.
+func NewServer(service Service, authenticate func(http.Handler) http.Handler, authorizer Authorizer, errorMapper ErrorMapper, middlewares ...func(http.Handler) http.Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Use(middlewares...)
+
+	if authenticate == nil {
+		authenticate = NilMiddleware()
+	}
+
+	s := &server{service, errorMapper}
+
+	r.With(authenticate).Post("/jobs", s.startJobHandler)
+	r.With(authenticate).Get("/ping", s.pingHandler)
+	r.With(authenticate).Post("/users", s.createUserHandler)
+	r.With(authenticate).Get("/users/count", s.countUsersHandler)
+	r.With(authenticate).Get("/users/{id}", s.getUserHandler)
+	r.With(authenticate).Delete("/users/{id}", s.deleteUserHandler)
+	return r
+}
+
+// ValidateResponses returns a middleware that checks the responses of the
+// operations against the spec. Violations are logged, if fail is set the
+// response is replaced by a problem with the status 500.
+func ValidateResponses(fail bool) func(http.Handler) http.Handler {
+	return validateResponses(operationSpecs, fail)
+}
+
+// operationSpecs are the declared responses of the operations by method and
+// route pattern.
+var operationSpecs = map[string]*operationSpec{
+	"POST /jobs": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"202": {body: func() interface{} { return new(*model.StartJob202Result) }},
+			"503": {},
+		},
+	},
+	"GET /ping": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"200": {},
+		},
+	},
+	"POST /users": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"201":     {body: func() interface{} { return new(*model.User) }},
+			"409":     {body: func() interface{} { return new(*model.Error) }},
+			"default": {body: func() interface{} { return new(*model.Error) }},
+		},
+	},
+	"GET /users/count": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"204": {},
+		},
+	},
+	"GET /users/{id}": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"200": {body: func() interface{} { return new(*model.User) }},
+			"404": {body: func() interface{} { return new(*model.Error) }},
+		},
+	},
+	"DELETE /users/{id}": {
+		produces: []string{"application/json"},
+		responses: map[string]responseSpec{
+			"204": {},
+			"404": {},
+		},
+	},
+}
+
+type server struct {
+	service     Service
+	errorMapper ErrorMapper
+}
+
+// serviceError maps an error of the service to a problem.
+func (s *server) serviceError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if s.errorMapper != nil {
+		if p := s.errorMapper(err); p != nil {
+			return p
+		}
+	}
+	if p := MapErrors(err); p != nil {
+		return p
+	}
+	return err
+}
+
+// MapErrors maps the sentinel errors and error types of the model package
+// to problems, other errors are mapped to nil.
+func MapErrors(err error) *Problem {
+	var validationErrors model.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+		return p.WithExtension("errors", validationErrors.Messages())
+	}
+
+	var status int
+	switch {
+	case errors.Is(err, model.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, model.ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, model.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, model.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, model.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, model.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	default:
+		return nil
+	}
+
+	p := NewProblem(status, "", err.Error()).WithCause(err)
+	var coded *model.CodedError
+	if errors.As(err, &coded) {
+		p.Code = coded.Code
+	}
+	return p
+}
+
+func (s *server) startJobHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	result, err := s.service.StartJob(r.Context())
+	respond(w, r, enc, result, s.serviceError(err))
+}
+
+func (s *server) pingHandler(w http.ResponseWriter, r *http.Request) {
+	emptyResponse(w, http.StatusOK, s.serviceError(s.service.Ping(r.Context())))
+}
+
+func (s *server) createUserHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 33554432)
+
+	var userP *model.User
+	if err := decodeBody(r, []string{"application/json"}, &userP); err != nil {
+		bodyError(w, err)
+		return
+	}
+
+	if validateBody(w, userP) {
+		return
+	}
+
+	result, err := s.service.CreateUser(r.Context(), userP)
+	respond(w, r, enc, result, s.serviceError(err))
+}
+
+func (s *server) countUsersHandler(w http.ResponseWriter, r *http.Request) {
+	result, err := s.service.CountUsers(r.Context())
+	respond(w, r, nil, result, s.serviceError(err))
+}
+
+func (s *server) getUserHandler(w http.ResponseWriter, r *http.Request) {
+	enc, err := negotiate(r, []string{"application/json"})
+	if err != nil {
+		JSONErrorStatus(w, http.StatusNotAcceptable, err)
+		return
+	}
+	idP := chi.URLParam(r, "id")
+
+	result, err := s.service.GetUser(r.Context(), idP)
+	respond(w, r, enc, result, s.serviceError(err))
+}
+
+func (s *server) deleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	idP := chi.URLParam(r, "id")
+
+	result, err := s.service.DeleteUser(r.Context(), idP)
+	respond(w, r, nil, result, s.serviceError(err))
+}
+
+// StartJobResponse is one of the responses of the startJob operation.
+type StartJobResponse interface {
+	responder
+	isStartJobResponse()
+}
+
+// StartJob202Response is the 202 response of the startJob operation.
+type StartJob202Response struct {
+	Body *model.StartJob202Result
+}
+
+// StartJobResponse202 returns the 202 response of the startJob operation.
+func StartJobResponse202(body *model.StartJob202Result) *StartJob202Response {
+	return &StartJob202Response{Body: body}
+}
+
+func (*StartJob202Response) isStartJobResponse() {}
+
+func (r *StartJob202Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	e.write(w, 202, r.Body)
+}
+
+// StartJob503Response is the 503 response of the startJob operation.
+type StartJob503Response struct {
+	RetryAfter *int
+}
+
+// StartJobResponse503 returns the 503 response of the startJob operation.
+func StartJobResponse503() *StartJob503Response {
+	return &StartJob503Response{}
+}
+
+func (*StartJob503Response) isStartJobResponse() {}
+
+func (r *StartJob503Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	if r.RetryAfter != nil {
+		w.Header().Set("Retry-After", formatValue(*r.RetryAfter))
+	}
+	w.WriteHeader(503)
+}
+
+// CreateUserResponse is one of the responses of the createUser operation.
+type CreateUserResponse interface {
+	responder
+	isCreateUserResponse()
+}
+
+// CreateUser201Response is the 201 response of the createUser operation.
+type CreateUser201Response struct {
+	Body     *model.User
+	Location string
+}
+
+// CreateUserResponse201 returns the 201 response of the createUser operation.
+func CreateUserResponse201(body *model.User) *CreateUser201Response {
+	return &CreateUser201Response{Body: body}
+}
+
+func (*CreateUser201Response) isCreateUserResponse() {}
+
+func (r *CreateUser201Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	if r.Location != "" {
+		w.Header().Set("Location", r.Location)
+	}
+	e.write(w, 201, r.Body)
+}
+
+// CreateUser409Response is the 409 response of the createUser operation.
+type CreateUser409Response struct {
+	Body *model.Error
+}
+
+// CreateUserResponse409 returns the 409 response of the createUser operation.
+func CreateUserResponse409(body *model.Error) *CreateUser409Response {
+	return &CreateUser409Response{Body: body}
+}
+
+func (*CreateUser409Response) isCreateUserResponse() {}
+
+func (r *CreateUser409Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	e.write(w, 409, r.Body)
+}
+
+// CreateUserDefaultResponse is the default response of the createUser operation.
+type CreateUserDefaultResponse struct {
+	Status int
+	Body   *model.Error
+}
+
+// CreateUserResponseDefault returns the default response of the createUser operation.
+func CreateUserResponseDefault(status int, body *model.Error) *CreateUserDefaultResponse {
+	return &CreateUserDefaultResponse{Status: status, Body: body}
+}
+
+func (*CreateUserDefaultResponse) isCreateUserResponse() {}
+
+func (r *CreateUserDefaultResponse) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	e.write(w, r.Status, r.Body)
+}
+
+// CountUsersResponse is one of the responses of the countUsers operation.
+type CountUsersResponse interface {
+	responder
+	isCountUsersResponse()
+}
+
+// CountUsers204Response is the 204 response of the countUsers operation.
+type CountUsers204Response struct {
+	XRoles      []string
+	XTotalCount *int64
+}
+
+// CountUsersResponse204 returns the 204 response of the countUsers operation.
+func CountUsersResponse204() *CountUsers204Response {
+	return &CountUsers204Response{}
+}
+
+func (*CountUsers204Response) isCountUsersResponse() {}
+
+func (r *CountUsers204Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	if len(r.XRoles) > 0 {
+		w.Header().Set("X-Roles", formatValues(r.XRoles, "|"))
+	}
+	if r.XTotalCount != nil {
+		w.Header().Set("X-Total-Count", formatValue(*r.XTotalCount))
+	}
+	w.WriteHeader(204)
+}
+
+// GetUserResponse is one of the responses of the getUser operation.
+type GetUserResponse interface {
+	responder
+	isGetUserResponse()
+}
+
+// GetUser200Response is the 200 response of the getUser operation.
+type GetUser200Response struct {
+	Body         *model.User
+	Etag         string
+	LastModified *time.Time
+}
+
+// GetUserResponse200 returns the 200 response of the getUser operation.
+func GetUserResponse200(body *model.User) *GetUser200Response {
+	return &GetUser200Response{Body: body}
+}
+
+func (*GetUser200Response) isGetUserResponse() {}
+
+func (r *GetUser200Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	if r.Etag != "" {
+		w.Header().Set("ETag", r.Etag)
+	}
+	if r.LastModified != nil {
+		w.Header().Set("Last-Modified", formatValue(*r.LastModified))
+	}
+	e.write(w, 200, r.Body)
+}
+
+// GetUser404Response is the 404 response of the getUser operation.
+type GetUser404Response struct {
+	Body *model.Error
+}
+
+// GetUserResponse404 returns the 404 response of the getUser operation.
+func GetUserResponse404(body *model.Error) *GetUser404Response {
+	return &GetUser404Response{Body: body}
+}
+
+func (*GetUser404Response) isGetUserResponse() {}
+
+func (r *GetUser404Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	e.write(w, 404, r.Body)
+}
+
+// DeleteUserResponse is one of the responses of the deleteUser operation.
+type DeleteUserResponse interface {
+	responder
+	isDeleteUserResponse()
+}
+
+// DeleteUser204Response is the 204 response of the deleteUser operation.
+type DeleteUser204Response struct{}
+
+// DeleteUserResponse204 returns the 204 response of the deleteUser operation.
+func DeleteUserResponse204() *DeleteUser204Response {
+	return &DeleteUser204Response{}
+}
+
+func (*DeleteUser204Response) isDeleteUserResponse() {}
+
+func (r *DeleteUser204Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	w.WriteHeader(204)
+}
+
+// DeleteUser404Response is the 404 response of the deleteUser operation.
+type DeleteUser404Response struct{}
+
+// DeleteUserResponse404 returns the 404 response of the deleteUser operation.
+func DeleteUserResponse404() *DeleteUser404Response {
+	return &DeleteUser404Response{}
+}
+
+func (*DeleteUser404Response) isDeleteUserResponse() {}
+
+func (r *DeleteUser404Response) writeResponse(w http.ResponseWriter, req *http.Request, e *encoder) {
+	w.WriteHeader(404)
+}
@@ -0,0 +1,66 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "StartJob",
+		Args: Args{Method: "Post", URL: "/jobs"},
+		Want: Want{},
+	},
+
+	{
+		Name: "Ping",
+		Args: Args{Method: "Get", URL: "/ping"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "CreateUser",
+		Args: Args{Method: "Post", URL: "/users"},
+		Want: Want{},
+	},
+
+	{
+		Name: "CountUsers",
+		Args: Args{Method: "Get", URL: "/users/count"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "GetUser",
+		Args: Args{Method: "Get", URL: "/users/%7Bid%7D"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "DeleteUser",
+		Args: Args{Method: "Delete", URL: "/users/%7Bid%7D"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+}
@@ -0,0 +1,55 @@
+package model
+
+type Error struct {
+	Message *string `json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// Validate returns an error if m does not match the Error schema.
+func (m *Error) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Error) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type StartJob202Result struct {
+	ID *string `json:"id,omitempty" xml:"id,omitempty"`
+}
+
+// Validate returns an error if m does not match the StartJob202Result schema.
+func (m *StartJob202Result) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *StartJob202Result) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
+
+type User struct {
+	Name string `json:"name" xml:"name"`
+}
+
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
@@ -0,0 +1,205 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationError describes a value that does not match its schema.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors contains all validation errors of a value.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	return strings.Join(e.Messages(), "; ")
+}
+
+// Messages returns the messages of all validation errors.
+func (e ValidationErrors) Messages() []string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+// Validator collects validation errors. It is used by the generated Validate
+// methods and to validate request parameters.
+type Validator struct {
+	errors ValidationErrors
+}
+
+// Add adds a validation error for the value at path.
+func (v *Validator) Add(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "(root)"
+	}
+	v.errors = append(v.errors, &ValidationError{Field: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns the collected validation errors as ValidationErrors or nil.
+func (v *Validator) Err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return v.errors
+}
+
+func (v *Validator) Required(path string) {
+	v.Add(path, "is required")
+}
+
+func (v *Validator) Minimum(path string, value, minimum float64) {
+	if value < minimum {
+		v.Add(path, "must be greater than or equal to %v", minimum)
+	}
+}
+
+func (v *Validator) ExclusiveMinimum(path string, value, minimum float64) {
+	if value <= minimum {
+		v.Add(path, "must be greater than %v", minimum)
+	}
+}
+
+func (v *Validator) Maximum(path string, value, maximum float64) {
+	if value > maximum {
+		v.Add(path, "must be less than or equal to %v", maximum)
+	}
+}
+
+func (v *Validator) ExclusiveMaximum(path string, value, maximum float64) {
+	if value >= maximum {
+		v.Add(path, "must be less than %v", maximum)
+	}
+}
+
+func (v *Validator) MultipleOf(path string, value, divisor float64) {
+	quotient := value / divisor
+	if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+		v.Add(path, "must be a multiple of %v", divisor)
+	}
+}
+
+func (v *Validator) MinLength(path, value string, minimum int) {
+	if utf8.RuneCountInString(value) < minimum {
+		v.Add(path, "must be at least %d characters long", minimum)
+	}
+}
+
+func (v *Validator) MaxLength(path, value string, maximum int) {
+	if utf8.RuneCountInString(value) > maximum {
+		v.Add(path, "must be at most %d characters long", maximum)
+	}
+}
+
+var patterns sync.Map
+
+func (v *Validator) Pattern(path, value, pattern string) {
+	re, ok := patterns.Load(pattern)
+	if !ok {
+		re, _ = patterns.LoadOrStore(pattern, regexp.MustCompile(pattern))
+	}
+	if !re.(*regexp.Regexp).MatchString(value) {
+		v.Add(path, "must match pattern %s", pattern)
+	}
+}
+
+var formats = map[string]func(string) bool{
+	"uuid": regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+	"email": func(s string) bool {
+		_, err := mail.ParseAddress(s)
+		return err == nil
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	},
+}
+
+// Format adds an error if value is not valid in the uuid, email or uri
+// format.
+func (v *Validator) Format(path, value, format string) {
+	if valid, ok := formats[format]; ok && !valid(value) {
+		v.Add(path, "must be a valid %s", format)
+	}
+}
+
+func (v *Validator) IPv4(path string, value net.IP) {
+	if value.To4() == nil {
+		v.Add(path, "must be a valid ipv4 address")
+	}
+}
+
+func (v *Validator) IPv6(path string, value net.IP) {
+	if value.To16() == nil || value.To4() != nil {
+		v.Add(path, "must be a valid ipv6 address")
+	}
+}
+
+func (v *Validator) MinItems(path string, length, minimum int) {
+	if length < minimum {
+		v.Add(path, "must have at least %d items", minimum)
+	}
+}
+
+func (v *Validator) MaxItems(path string, length, maximum int) {
+	if length > maximum {
+		v.Add(path, "must have at most %d items", maximum)
+	}
+}
+
+func (v *Validator) MinProperties(path string, length, minimum int) {
+	if length < minimum {
+		v.Add(path, "must have at least %d properties", minimum)
+	}
+}
+
+func (v *Validator) MaxProperties(path string, length, maximum int) {
+	if length > maximum {
+		v.Add(path, "must have at most %d properties", maximum)
+	}
+}
+
+// UniqueItems adds an error if two items have the same JSON representation.
+func UniqueItems[T any](v *Validator, path string, items []T) {
+	seen := map[string]bool{}
+	for _, item := range items {
+		b, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		if seen[string(b)] {
+			v.Add(path, "must not contain duplicate items")
+			return
+		}
+		seen[string(b)] = true
+	}
+}
+
+func field(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func index(path string, i int) string {
+	return field(path, strconv.Itoa(i))
+}
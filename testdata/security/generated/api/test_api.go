@@ -0,0 +1,42 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "Health",
+		Args: Args{Method: "Get", URL: "/health"},
+		Want: Want{
+			Status: 204,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "ListTickets",
+		Args: Args{Method: "Get", URL: "/tickets"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "CreateTicket",
+		Args: Args{Method: "Post", URL: "/tickets"},
+		Want: Want{},
+	},
+}
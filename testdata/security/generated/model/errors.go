@@ -0,0 +1,48 @@
+package model
+
+import "errors"
+
+// The sentinel errors of services, the server maps them to their HTTP
+// status codes. Services wrap them to add details, like
+// fmt.Errorf("user %s: %w", id, model.ErrNotFound).
+var (
+	ErrInvalid            = errors.New("invalid")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// NotFoundError is returned if a resource does not exist, it matches
+// ErrNotFound.
+type NotFoundError struct {
+	Resource string
+	ID       string
+}
+
+func (e *NotFoundError) Error() string {
+	if e.ID == "" {
+		return e.Resource + " not found"
+	}
+	return e.Resource + " " + e.ID + " not found"
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// CodedError adds a machine-readable code to an error, which is written in
+// the code member of the problem response.
+type CodedError struct {
+	Code string
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,19 @@
+package model
+
+type Ticket struct {
+	Name string `json:"name" xml:"name"`
+}
+
+// Validate returns an error if m does not match the Ticket schema.
+func (m *Ticket) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *Ticket) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+}
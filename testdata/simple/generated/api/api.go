@@ -1,15 +1,17 @@
 package api
 
 import (
-	"bytes"
-	"encoding/json"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi"
-	"github.com/xeipuuv/gojsonschema"
 )
 
 type HTTPError struct {
@@ -25,47 +27,67 @@ func (e *HTTPError) Unwrap() error {
 	return e.Internal
 }
 
-func parseURLInt64(r *http.Request, s string) (int64, error) {
-	i, err := strconv.ParseInt(chi.URLParam(r, s), 10, 64)
-	if err != nil {
-		return 0, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
-	}
-	return i, nil
+func parseURLValue[T any](r *http.Request, key string, parse func(string) (T, error)) (T, error) {
+	return parseValue(chi.URLParam(r, key), parse)
 }
 
-func parseURLInt(r *http.Request, s string) (int, error) {
-	i, err := strconv.Atoi(chi.URLParam(r, s))
-	if err != nil {
-		return 0, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
+func parseQueryValue[T any](r *http.Request, key string, parse func(string) (T, error)) (T, error) {
+	values, ok := r.URL.Query()[key]
+	if !ok {
+		var v T
+		return v, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, fmt.Errorf("query parameter %s is required", key)})
 	}
-	return i, nil
+	return parseValue(values[0], parse)
 }
 
-func parseQueryInt(r *http.Request, s string) (int, error) {
-	i, err := strconv.Atoi(r.URL.Query().Get(s))
+func parseQueryOptionalValue[T any](r *http.Request, key string, parse func(string) (T, error)) (*T, error) {
+	values, ok := r.URL.Query()[key]
+	if !ok {
+		return nil, nil
+	}
+	v, err := parseValue(values[0], parse)
 	if err != nil {
-		return 0, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
+		return nil, err
 	}
-	return i, nil
+	return &v, nil
 }
 
-func parseQueryBool(r *http.Request, s string) (bool, error) {
-	b, err := strconv.ParseBool(r.URL.Query().Get(s))
-	if err != nil {
-		return false, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
+// parseQueryDefaultValue parses a query parameter or its default value if
+// the parameter is missing.
+func parseQueryDefaultValue[T any](r *http.Request, key, def string, parse func(string) (T, error)) (T, error) {
+	values, ok := r.URL.Query()[key]
+	if !ok {
+		return parseValue(def, parse)
 	}
-	return b, nil
+	return parseValue(values[0], parse)
 }
 
-func parseQueryStringArray(r *http.Request, key string) ([]string, error) {
-	stringArray, ok := r.URL.Query()[key]
-	if !ok {
-		return nil, nil
+// parseQueryValueArray parses the items of all occurrences of a query
+// parameter. The occurrences are split by the separator of the collection
+// format, the multi format has no separator. Empty items are ignored.
+func parseQueryValueArray[T any](r *http.Request, key, separator string, maxItems int, parse func(string) (T, error)) ([]T, error) {
+	var items []string
+	for _, value := range r.URL.Query()[key] {
+		if separator == "" {
+			items = append(items, value)
+		} else {
+			items = append(items, strings.Split(value, separator)...)
+		}
+	}
+	items = removeEmpty(items)
+	if len(items) > maxItems {
+		return nil, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, fmt.Errorf("too many items in query parameter %s", key)})
 	}
-	if len(stringArray) > 1000 {
-		return nil, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, errors.New("too many items in query parameter")})
+
+	var values []T
+	for _, s := range items {
+		v, err := parseValue(s, parse)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
 	}
-	return removeEmpty(stringArray), nil
+	return values, nil
 }
 
 func removeEmpty(l []string) []string {
@@ -80,97 +102,225 @@ func removeEmpty(l []string) []string {
 	return stringArray
 }
 
-func parseQueryBoolArray(r *http.Request, key string) ([]bool, error) {
-	stringArray, ok := r.URL.Query()[key]
-	if !ok {
+func parseHeaderValue[T any](r *http.Request, key string, parse func(string) (T, error)) (T, error) {
+	values := r.Header.Values(key)
+	if len(values) == 0 {
+		var v T
+		return v, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, fmt.Errorf("header %s is required", key)})
+	}
+	return parseValue(values[0], parse)
+}
+
+func parseHeaderOptionalValue[T any](r *http.Request, key string, parse func(string) (T, error)) (*T, error) {
+	values := r.Header.Values(key)
+	if len(values) == 0 {
 		return nil, nil
 	}
-	if len(stringArray) > 1000 {
-		return nil, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, errors.New("too many items in query parameter")})
+	v, err := parseValue(values[0], parse)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// parseHeaderDefaultValue parses a header or its default value if the header
+// is missing.
+func parseHeaderDefaultValue[T any](r *http.Request, key, def string, parse func(string) (T, error)) (T, error) {
+	values := r.Header.Values(key)
+	if len(values) == 0 {
+		return parseValue(def, parse)
 	}
-	var boolArray []bool
-	for _, s := range stringArray {
+	return parseValue(values[0], parse)
+}
+
+// parseHeaderValueArray parses the comma separated items of all lines of a
+// header.
+func parseHeaderValueArray[T any](r *http.Request, key string, parse func(string) (T, error)) ([]T, error) {
+	var values []T
+	for _, line := range r.Header.Values(key) {
+		for _, s := range strings.Split(line, ",") {
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			v, err := parseValue(s, parse)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+func parseCookieValue[T any](r *http.Request, key string, parse func(string) (T, error)) (T, error) {
+	cookie, err := r.Cookie(key)
+	if err != nil {
+		var v T
+		return v, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, fmt.Errorf("cookie %s is required", key)})
+	}
+	return parseValue(cookie.Value, parse)
+}
+
+func parseCookieOptionalValue[T any](r *http.Request, key string, parse func(string) (T, error)) (*T, error) {
+	cookie, err := r.Cookie(key)
+	if err != nil {
+		return nil, nil
+	}
+	v, err := parseValue(cookie.Value, parse)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// parseCookieDefaultValue parses a cookie or its default value if the cookie
+// is missing.
+func parseCookieDefaultValue[T any](r *http.Request, key, def string, parse func(string) (T, error)) (T, error) {
+	cookie, err := r.Cookie(key)
+	if err != nil {
+		return parseValue(def, parse)
+	}
+	return parseValue(cookie.Value, parse)
+}
+
+// parseCookieValueArray parses the comma separated items of a cookie.
+func parseCookieValueArray[T any](r *http.Request, key string, parse func(string) (T, error)) ([]T, error) {
+	cookie, err := r.Cookie(key)
+	if err != nil {
+		return nil, nil
+	}
+
+	var values []T
+	for _, s := range strings.Split(cookie.Value, ",") {
 		if s == "" {
 			continue
 		}
-		b, err := strconv.ParseBool(s)
+		v, err := parseValue(s, parse)
 		if err != nil {
-			return nil, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
+			return nil, err
 		}
-		boolArray = append(boolArray, b)
+		values = append(values, v)
 	}
-
-	return boolArray, nil
+	return values, nil
 }
 
-func parseQueryOptionalBool(r *http.Request, key string) (*bool, error) {
-	if exists := r.URL.Query().Has(key); exists {
-		var value bool
-		v := r.URL.Query().Get(key)
-		if v == "" {
-			value = true
-			return &value, nil
-		}
-		b, err := strconv.ParseBool(v)
-		if err != nil {
-			return nil, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
-		} else {
-			value = b
-			return &value, nil
+// defaultValues returns the parsed default items of an array parameter if
+// no items were sent. The defaults are checked by the generator, so items
+// that cannot be parsed are skipped.
+func defaultValues[T any](values []T, def []string, parse func(string) (T, error)) []T {
+	if len(values) > 0 {
+		return values
+	}
+	for _, s := range def {
+		if v, err := parse(s); err == nil {
+			values = append(values, v)
 		}
 	}
+	return values
+}
+
+func parseValue[T any](s string, parse func(string) (T, error)) (T, error) {
+	v, err := parse(s)
+	if err != nil {
+		return v, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
+	}
+	return v, nil
+}
+
+// The parse functions convert single parameter values to the Go type of
+// their schema.
+
+func parseString(s string) (string, error) {
+	return s, nil
+}
+
+func parseInt(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+func parseInt32(s string) (int32, error) {
+	i, err := strconv.ParseInt(s, 10, 32)
+	return int32(i), err
+}
+
+func parseInt64(s string) (int64, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
 
-	return nil, nil
+func parseFloat32(s string) (float32, error) {
+	f, err := strconv.ParseFloat(s, 32)
+	return float32(f), err
 }
 
-func parseQueryOptionalInt(r *http.Request, key string) (*int, error) {
-	s := r.URL.Query().Get(key)
+func parseFloat64(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseBool parses a boolean, a flag without value is true.
+func parseBool(s string) (bool, error) {
 	if s == "" {
-		return nil, nil
+		return true, nil
 	}
+	return strconv.ParseBool(s)
+}
 
-	i, err := strconv.Atoi(s)
-	if err != nil {
-		return nil, fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
-	}
-	return &i, nil
+func parseDateTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
+
+func parseBytes(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(s)
 }
 
-func parseQueryOptionalStringArray(r *http.Request, key string) ([]string, error) {
-	return parseQueryStringArray(r, key)
+func parseIP(s string) (net.IP, error) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid ip address %q", s)
+	}
+	return ip, nil
 }
 
-func parseQueryOptionalBoolArray(r *http.Request, key string) ([]bool, error) {
-	return parseQueryBoolArray(r, key)
+// formFiles returns the uploaded files of a form field, forms that are not
+// multipart have no files.
+func formFiles(r *http.Request, key string) []*multipart.FileHeader {
+	if r.MultipartForm == nil {
+		return nil
+	}
+	return r.MultipartForm.File[key]
 }
 
-func parseBody(b []byte, i interface{}) error {
-	dec := json.NewDecoder(bytes.NewBuffer(b))
-	err := dec.Decode(i)
-	if err != nil {
-		return fmt.Errorf("%w", &HTTPError{http.StatusUnprocessableEntity, err})
+// bodyError writes the error of decoding a request body, unsupported media
+// types and too large bodies are reported with their status.
+func bodyError(w http.ResponseWriter, err error) {
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		JSONErrorStatus(w, http.StatusRequestEntityTooLarge, err)
+		return
 	}
-	return nil
+	JSONError(w, err)
 }
 
+// JSONError writes the error of an invalid request as problem. HTTP errors
+// keep their status, other errors have the status 400.
 func JSONError(w http.ResponseWriter, err error) {
+	var httpError *HTTPError
+	if errors.As(err, &httpError) {
+		JSONErrorStatus(w, httpError.Status, httpError.Internal)
+		return
+	}
 	JSONErrorStatus(w, http.StatusBadRequest, err)
 }
 
+// JSONErrorStatus writes the error as problem with the status.
 func JSONErrorStatus(w http.ResponseWriter, status int, err error) {
-	w.WriteHeader(status)
-	b, _ := json.Marshal(map[string]string{"error": err.Error()})
-	w.Write(b)
+	WriteProblem(w, NewProblem(status, "", err.Error()).WithCause(err))
 }
 
-func response(w http.ResponseWriter, v interface{}, err error) {
+// response writes the result of a service in the negotiated media type or
+// its error as problem.
+func response(w http.ResponseWriter, e *encoder, v interface{}, err error) {
 	if err != nil {
-		var httpError *HTTPError
-		if errors.As(err, &httpError) {
-			JSONErrorStatus(w, httpError.Status, httpError.Internal)
-			return
-		}
-		JSONError(w, err)
+		WriteProblem(w, problem(err))
 		return
 	}
 
@@ -178,31 +328,85 @@ func response(w http.ResponseWriter, v interface{}, err error) {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	b, _ := json.Marshal(v)
-	w.Write(b)
+	e.write(w, http.StatusOK, v)
 }
 
-func validateSchema(body []byte, schema *gojsonschema.Schema, w http.ResponseWriter) bool {
-	jl := gojsonschema.NewBytesLoader(body)
-	validationResult, err := schema.Validate(jl)
+// emptyResponse writes the status of a response without body or the error
+// of a service as problem.
+func emptyResponse(w http.ResponseWriter, status int, err error) {
 	if err != nil {
-		JSONError(w, err)
-		return true
+		WriteProblem(w, problem(err))
+		return
 	}
-	if !validationResult.Valid() {
-		w.WriteHeader(http.StatusUnprocessableEntity)
+	w.WriteHeader(status)
+}
 
-		var validationErrors []string
-		for _, valdiationError := range validationResult.Errors() {
-			validationErrors = append(validationErrors, valdiationError.String())
-		}
+// responder is implemented by the response types of operations with
+// multiple responses.
+type responder interface {
+	writeResponse(w http.ResponseWriter, r *http.Request, e *encoder)
+}
+
+// respond writes the response returned by a service or its error.
+func respond(w http.ResponseWriter, r *http.Request, e *encoder, res responder, err error) {
+	if err != nil {
+		response(w, e, nil, err)
+		return
+	}
+	if res == nil {
+		JSONErrorStatus(w, http.StatusInternalServerError, errors.New("no response"))
+		return
+	}
+	res.writeResponse(w, r, e)
+}
 
-		b, _ := json.Marshal(map[string]interface{}{"error": "wrong input", "errors": validationErrors})
-		w.Write(b)
-		return true
+// formatValue returns the string representation of a response header value.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case fmt.Stringer:
+		return v.String()
 	}
-	return false
+	return fmt.Sprint(v)
+}
+
+// formatValues joins the string representations of the items of an array
+// response header.
+func formatValues[T any](values []T, separator string) string {
+	items := make([]string, 0, len(values))
+	for _, v := range values {
+		items = append(items, formatValue(v))
+	}
+	return strings.Join(items, separator)
+}
+
+// validateBody writes the validation errors of a decoded request body and
+// returns true if the body is invalid.
+func validateBody(w http.ResponseWriter, body interface{ Validate() error }) bool {
+	return validationError(w, body.Validate())
+}
+
+// validationError writes the validation errors of a request and returns true
+// if there are any.
+func validationError(w http.ResponseWriter, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	validationErrors := []string{err.Error()}
+	var messages interface{ Messages() []string }
+	if errors.As(err, &messages) {
+		validationErrors = messages.Messages()
+	}
+
+	p := NewProblem(http.StatusUnprocessableEntity, "invalid_input", "wrong input").WithCause(err)
+	WriteProblem(w, p.WithExtension("errors", validationErrors))
+	return true
 }
 
 func NilMiddleware() func(next http.Handler) http.Handler {
@@ -212,7 +416,3 @@ func NilMiddleware() func(next http.Handler) http.Handler {
 		})
 	}
 }
-
-func IgnoreRoles(_ []string) func(next http.Handler) http.Handler {
-	return NilMiddleware()
-}
@@ -0,0 +1,494 @@
+package api
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Codec encodes response bodies and decodes request bodies of a media type.
+type Codec interface {
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		"application/json":                  jsonCodec{},
+		"application/xml":                   xmlCodec{},
+		"text/xml":                          xmlCodec{},
+		"application/yaml":                  yamlCodec{},
+		"application/x-yaml":                yamlCodec{},
+		"text/yaml":                         yamlCodec{},
+		"text/plain":                        textCodec{},
+		"application/x-www-form-urlencoded": formCodec{},
+	}
+)
+
+// RegisterCodec registers the codec of a media type, an existing codec of
+// the media type is replaced.
+func RegisterCodec(mediaType string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[strings.ToLower(mediaType)] = codec
+}
+
+func lookupCodec(mediaType string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	codec, ok := codecs[mediaType]
+	if !ok && strings.HasSuffix(mediaType, "+json") {
+		// structured syntax suffixes, like application/problem+json
+		codec, ok = codecs["application/json"]
+	}
+	return codec, ok
+}
+
+// encoder writes response bodies in the media type selected by negotiate.
+type encoder struct {
+	mediaType string
+	codec     Codec
+}
+
+// negotiate selects the media type of the response from the Accept header
+// of the request and the media types the operation produces. Requests
+// without Accept header get the first media type.
+func negotiate(r *http.Request, produces []string) (*encoder, error) {
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		for _, mediaType := range produces {
+			if codec, ok := lookupCodec(mediaType); ok {
+				return &encoder{mediaType, codec}, nil
+			}
+		}
+		return nil, errors.New("no supported media type")
+	}
+
+	ranges := parseAccept(strings.Join(accept, ","))
+	var best *encoder
+	bestQ := 0.0
+	for _, mediaType := range produces {
+		q := acceptQuality(ranges, mediaType)
+		if q <= bestQ {
+			continue
+		}
+		if codec, ok := lookupCodec(mediaType); ok {
+			best, bestQ = &encoder{mediaType, codec}, q
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("none of the media types %s is acceptable", strings.Join(produces, ", "))
+	}
+	return best, nil
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept returns the media ranges of an Accept header, the most
+// specific ranges first.
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType, q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return strings.Count(ranges[i].mediaType, "*") < strings.Count(ranges[j].mediaType, "*")
+	})
+	return ranges
+}
+
+// acceptQuality returns the quality of the most specific media range that
+// matches the media type, or 0 if it is not acceptable.
+func acceptQuality(ranges []mediaRange, mediaType string) float64 {
+	for _, r := range ranges {
+		typ, subtype, _ := strings.Cut(r.mediaType, "/")
+		mediaTyp, mediaSubtype, _ := strings.Cut(mediaType, "/")
+		if (typ == "*" || typ == mediaTyp) && (subtype == "*" || subtype == mediaSubtype) {
+			return r.q
+		}
+	}
+	return 0
+}
+
+// write writes v with the status code in the negotiated media type. The
+// nil encoder of operations without negotiated media type writes JSON.
+func (e *encoder) write(w http.ResponseWriter, status int, v interface{}) {
+	if e == nil {
+		e = &encoder{"application/json", jsonCodec{}}
+	}
+	w.Header().Set("Content-Type", e.mediaType)
+	w.WriteHeader(status)
+	e.codec.Encode(w, v)
+}
+
+// requestMediaType returns the media type of the request body, which has to
+// be one of the media types the operation consumes. Requests without
+// Content-Type have the first media type.
+func requestMediaType(r *http.Request, consumes []string) (string, error) {
+	mediaType := ""
+	if len(consumes) > 0 {
+		mediaType = consumes[0]
+	}
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return "", &HTTPError{http.StatusUnsupportedMediaType, err}
+		}
+		mediaType = parsed
+	}
+
+	if !containsMediaType(consumes, mediaType) {
+		return "", &HTTPError{http.StatusUnsupportedMediaType, fmt.Errorf("unsupported media type %s", mediaType)}
+	}
+	return mediaType, nil
+}
+
+// decodeBody decodes the request body with the codec of its media type.
+func decodeBody(r *http.Request, consumes []string, v interface{}) error {
+	mediaType, err := requestMediaType(r, consumes)
+	if err != nil {
+		return err
+	}
+	codec, ok := lookupCodec(mediaType)
+	if !ok {
+		return &HTTPError{http.StatusUnsupportedMediaType, fmt.Errorf("unsupported media type %s", mediaType)}
+	}
+
+	return codec.Decode(r.Body, v)
+}
+
+// containsMediaType returns true if one of the media types or media ranges
+// matches the media type.
+func containsMediaType(mediaTypes []string, mediaType string) bool {
+	for _, m := range mediaTypes {
+		if acceptQuality([]mediaRange{{strings.ToLower(m), 1}}, strings.ToLower(mediaType)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// xmlCodec encodes arrays in an items root element, as an XML document has
+// a single root.
+type xmlCodec struct{}
+
+var xmlItems = xml.StartElement{Name: xml.Name{Local: "items"}}
+
+func (xmlCodec) Encode(w io.Writer, v interface{}) error {
+	encoder := xml.NewEncoder(w)
+	if !isList(reflect.ValueOf(v)) {
+		return encoder.Encode(v)
+	}
+
+	if err := encoder.EncodeToken(xmlItems); err != nil {
+		return err
+	}
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	if err := encoder.EncodeToken(xmlItems.End()); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+func (xmlCodec) Decode(r io.Reader, v interface{}) error {
+	decoder := xml.NewDecoder(r)
+	list := reflect.ValueOf(v)
+	if list.Kind() != reflect.Pointer || !isList(list) {
+		return decoder.Decode(v)
+	}
+	list = list.Elem()
+
+	// the items are the children of the root element
+	inRoot := false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if !inRoot {
+				inRoot = true
+				continue
+			}
+			item := reflect.New(list.Type().Elem())
+			if err := decoder.DecodeElement(item.Interface(), &token); err != nil {
+				return err
+			}
+			list.Set(reflect.Append(list, item.Elem()))
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// isList returns true if v is a slice or a pointer to a slice, byte slices
+// are no lists.
+func isList(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8
+}
+
+// yamlCodec converts values from and to JSON, so the JSON names and
+// marshalers of the models are used.
+type yamlCodec struct{}
+
+func (yamlCodec) Encode(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	return yaml.NewEncoder(w).Encode(value)
+}
+
+func (yamlCodec) Decode(r io.Reader, v interface{}) error {
+	var value interface{}
+	if err := yaml.NewDecoder(r).Decode(&value); err != nil {
+		return err
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// textCodec encodes strings, byte slices, text marshalers and other values
+// formatted with fmt. It decodes into strings, byte slices and text
+// unmarshalers.
+type textCodec struct{}
+
+func (textCodec) Encode(w io.Writer, v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		_, err := io.WriteString(w, v)
+		return err
+	case *string:
+		_, err := io.WriteString(w, *v)
+		return err
+	case []byte:
+		_, err := w.Write(v)
+		return err
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	_, err := fmt.Fprint(w, v)
+	return err
+}
+
+func (textCodec) Decode(r io.Reader, v interface{}) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case *string:
+		*v = string(b)
+	case **string:
+		s := string(b)
+		*v = &s
+	case *[]byte:
+		*v = b
+	case encoding.TextUnmarshaler:
+		return v.UnmarshalText(b)
+	default:
+		return fmt.Errorf("cannot decode text into %T", v)
+	}
+	return nil
+}
+
+// formCodec encodes and decodes URL encoded forms. The fields are matched by
+// their JSON names, so form bodies decode into the generated models.
+type formCodec struct{}
+
+func (formCodec) Encode(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return fmt.Errorf("cannot encode %T as form", v)
+	}
+
+	values := url.Values{}
+	for key, field := range fields {
+		if items, ok := field.([]interface{}); ok {
+			for _, item := range items {
+				values.Add(key, fmt.Sprint(item))
+			}
+			continue
+		}
+		values.Set(key, fmt.Sprint(field))
+	}
+	_, err = io.WriteString(w, values.Encode())
+	return err
+}
+
+func (formCodec) Decode(r io.Reader, v interface{}) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("cannot decode form into %T", v)
+	}
+	rv = rv.Elem()
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode form into %T", v)
+	}
+	return decodeFormStruct(rv, values)
+}
+
+// decodeFormStruct sets the fields of a struct, including the fields of
+// embedded structs, to the form values of their JSON names.
+func decodeFormStruct(rv reflect.Value, values url.Values) error {
+	for i := 0; i < rv.NumField(); i++ {
+		field, value := rv.Type().Field(i), rv.Field(i)
+		if field.Anonymous && value.Kind() == reflect.Struct {
+			if err := decodeFormStruct(value, values); err != nil {
+				return err
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		items, ok := values[name]
+		if !ok {
+			continue
+		}
+		if err := setFormValue(value, items); err != nil {
+			return fmt.Errorf("form field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setFormValue sets a value of a primitive type, a pointer to it or a slice
+// of it to the form values.
+func setFormValue(value reflect.Value, items []string) error {
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(items[0]))
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(value.Type().Elem())
+		if err := setFormValue(elem.Elem(), items); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			value.SetBytes([]byte(items[0]))
+			return nil
+		}
+		slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setFormValue(slice.Index(i), []string{item}); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	case reflect.String:
+		value.SetString(items[0])
+	case reflect.Bool:
+		b, err := parseBool(items[0])
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(items[0], 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(items[0], 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(items[0], value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package api
+
+type Args struct {
+	Method string
+	URL    string
+	Data   interface{}
+}
+type Want struct {
+	Status int
+	Body   interface{}
+}
+
+var Tests = []struct {
+	Name string
+	Args Args
+	Want Want
+}{
+
+	{
+		Name: "ListUsers",
+		Args: Args{Method: "Get", URL: "/users?limit=10&q=bob"},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+
+	{
+		Name: "CreateUser",
+		Args: Args{Method: "Post", URL: "/users", Data: map[string]interface{}{"age": 42, "name": "bob", "tags": []interface{}{"a"}}},
+		Want: Want{
+			Status: 200,
+			Body:   nil,
+		},
+	},
+}
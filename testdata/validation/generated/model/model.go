@@ -0,0 +1,84 @@
+package model
+
+type Matrix [][]int
+
+// Validate returns an error if m does not match the Matrix schema.
+func (m Matrix) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m Matrix) validate(v *Validator, path string) {
+	v.MaxItems(path, len(m), 3)
+	for i0, item0 := range m {
+		for i1, item1 := range item0 {
+			v.Maximum(index(index(path, i0), i1), float64(item1), 9)
+		}
+	}
+}
+
+type User struct {
+	Age      *int              `json:"age,omitempty" xml:"age,omitempty"`
+	Codes    []int             `json:"codes,omitempty" xml:"codes,omitempty"`
+	Friends  []*User           `json:"friends,omitempty" xml:"friends,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty" xml:"labels,omitempty"`
+	Labels2  map[string]string `json:"labels2,omitempty" xml:"labels2,omitempty"`
+	Name     string            `json:"name" xml:"name"`
+	Nickname *string           `json:"nickname,omitempty" xml:"nickname,omitempty"`
+	Score    *float64          `json:"score,omitempty" xml:"score,omitempty"`
+	Tags     []string          `json:"tags" xml:"tags"`
+}
+
+// Validate returns an error if m does not match the User schema.
+func (m *User) Validate() error {
+	v := &Validator{}
+	m.validate(v, "")
+	return v.Err()
+}
+
+func (m *User) validate(v *Validator, path string) {
+	if m == nil {
+		v.Required(path)
+		return
+	}
+	if m.Age != nil {
+		v.Minimum(field(path, "age"), float64(*m.Age), 0)
+		v.Maximum(field(path, "age"), float64(*m.Age), 150)
+	}
+	UniqueItems(v, field(path, "codes"), m.Codes)
+	for i0, item0 := range m.Codes {
+		v.ExclusiveMinimum(index(field(path, "codes"), i0), float64(item0), 0)
+		v.MultipleOf(index(field(path, "codes"), i0), float64(item0), 5)
+	}
+	for i0, item0 := range m.Friends {
+		if item0 != nil {
+			item0.validate(v, index(field(path, "friends"), i0))
+		}
+	}
+	for key0, item0 := range m.Labels {
+		v.MaxLength(field(field(path, "labels"), key0), item0, 5)
+	}
+	if m.Labels2 != nil {
+		v.MinProperties(field(path, "labels2"), len(m.Labels2), 1)
+		v.MaxProperties(field(path, "labels2"), len(m.Labels2), 3)
+	}
+	v.MinLength(field(path, "name"), m.Name, 3)
+	v.MaxLength(field(path, "name"), m.Name, 20)
+	v.Pattern(field(path, "name"), m.Name, "^[a-z]+$")
+	if m.Nickname != nil {
+		v.MaxLength(field(path, "nickname"), *m.Nickname, 10)
+	}
+	if m.Score != nil {
+		v.Minimum(field(path, "score"), float64(*m.Score), 0.5)
+	}
+	if m.Tags == nil {
+		v.Required(field(path, "tags"))
+	} else {
+		v.MinItems(field(path, "tags"), len(m.Tags), 1)
+		v.MaxItems(field(path, "tags"), len(m.Tags), 5)
+		for i0, item0 := range m.Tags {
+			v.MinLength(index(field(path, "tags"), i0), item0, 1)
+		}
+	}
+}
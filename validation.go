@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// validation returns the Go statements that validate the struct field
+// m.<name> of the property name with the schema s.
+func validation(name string, s *Schema, required []string) string {
+	g := &validationGenerator{model: true}
+	g.value("m."+export(name), fmt.Sprintf("field(path, %s)", strconv.Quote(name)), s, goType(name, s, required), contains(required, name), 0)
+	return g.String()
+}
+
+// validationChecks returns the Go statements that validate m, which is of
+// the named type generated for the schema s.
+func validationChecks(s *Schema) string {
+	g := &validationGenerator{model: true}
+	g.checks("m", "path", s, 0)
+	return g.String()
+}
+
+// parameterValidation returns the Go statements that validate the parsed
+// value of a path, query, header or cookie parameter with a
+// model.Validator v.
+func parameterValidation(parameter Parameter) string {
+	if parameter.In == "body" || parameter.In == "formData" {
+		return ""
+	}
+
+	expr, path, s := parameterVar(parameter), strconv.Quote(parameter.Name), parameterSchema(parameter)
+
+	g := &validationGenerator{}
+	if plainString(parameter) {
+		// string parameters are read as plain strings, which are empty if
+		// the parameter is missing
+		g.checks(expr, path, s, 0)
+		if g.Len() > 0 && !parameter.Required {
+			return fmt.Sprintf("if %s != \"\" {\n%s}\n", expr, g.String())
+		}
+		return g.String()
+	}
+
+	g.value(expr, path, s, parameterType(parameter), parameter.Required, 0)
+	return g.String()
+}
+
+// validates returns true if a validate method is generated for the schema.
+func validates(s *Schema) bool {
+	return isObject(s) || isUnion(s) || isEnum(s) || s.Type == "array"
+}
+
+type validationGenerator struct {
+	strings.Builder
+	model bool // code is generated into the model package
+}
+
+func (g *validationGenerator) line(format string, args ...interface{}) {
+	fmt.Fprintf(g, format+"\n", args...)
+}
+
+// value writes the validation of expr, which is of the Go type typ. The path
+// is the Go expression of the JSON path of the value.
+func (g *validationGenerator) value(expr, path string, s *Schema, typ string, required bool, depth int) {
+	inner := &validationGenerator{model: g.model}
+	value := expr
+	if strings.HasPrefix(typ, "*") && s.Ref == "" {
+		value = "*" + expr
+	}
+	inner.checks(value, path, s, depth)
+
+	collection := strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[")
+	nilable := collection || strings.HasPrefix(typ, "*") || typ == "interface{}" || typ == "net.IP" || typ == "io.Reader"
+	if collection && !countChecks(s) {
+		// ranging over nil slices and maps is a no-op
+		nilable = false
+		if required {
+			g.line("if %s == nil {", expr)
+			g.line("v.Required(%s)", path)
+			g.line("}")
+		}
+	}
+
+	switch {
+	case nilable && required:
+		g.line("if %s == nil {", expr)
+		g.line("v.Required(%s)", path)
+		if inner.Len() > 0 {
+			g.line("} else {")
+			g.WriteString(inner.String())
+		}
+		g.line("}")
+	case nilable && inner.Len() > 0:
+		g.line("if %s != nil {", expr)
+		g.WriteString(inner.String())
+		g.line("}")
+	default:
+		g.WriteString(inner.String())
+	}
+}
+
+// checks writes the validation of the schema keywords for the non-nil value
+// expr.
+func (g *validationGenerator) checks(expr, path string, s *Schema, depth int) {
+	if s.Ref != "" {
+		// referenced types validate themselves, outside of the model
+		// package enums are already checked by parsing
+		if g.model && s.resolved != nil && validates(s.resolved) {
+			g.line("%s.validate(v, %s)", expr, path)
+		}
+		return
+	}
+
+	switch s.Type {
+	case "string":
+		switch formatType("", s) {
+		case "string":
+		case "net.IP":
+			switch s.Format {
+			case "ipv4":
+				g.line("v.IPv4(%s, %s)", path, expr)
+			case "ipv6":
+				g.line("v.IPv6(%s, %s)", path, expr)
+			}
+			return
+		default:
+			return
+		}
+		switch s.Format {
+		case "uuid", "email", "uri":
+			g.line("v.Format(%s, %s, %s)", path, expr, strconv.Quote(s.Format))
+		}
+		if s.MinLength != nil {
+			g.line("v.MinLength(%s, %s, %d)", path, expr, *s.MinLength)
+		}
+		if s.MaxLength != nil {
+			g.line("v.MaxLength(%s, %s, %d)", path, expr, *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			if _, err := regexp.Compile(s.Pattern); err != nil {
+				log.Printf("pattern %s is not supported: %s", s.Pattern, err)
+			} else {
+				g.line("v.Pattern(%s, %s, %s)", path, expr, strconv.Quote(s.Pattern))
+			}
+		}
+	case "number", "integer":
+		if s.Minimum != nil {
+			if s.ExclusiveMinimum {
+				g.line("v.ExclusiveMinimum(%s, float64(%s), %v)", path, expr, s.Minimum)
+			} else {
+				g.line("v.Minimum(%s, float64(%s), %v)", path, expr, s.Minimum)
+			}
+		}
+		if s.Maximum != nil {
+			if s.ExclusiveMaximum {
+				g.line("v.ExclusiveMaximum(%s, float64(%s), %v)", path, expr, s.Maximum)
+			} else {
+				g.line("v.Maximum(%s, float64(%s), %v)", path, expr, s.Maximum)
+			}
+		}
+		if s.MultipleOf != nil {
+			g.line("v.MultipleOf(%s, float64(%s), %v)", path, expr, s.MultipleOf)
+		}
+	case "array":
+		if s.MinItems != nil {
+			g.line("v.MinItems(%s, len(%s), %d)", path, expr, *s.MinItems)
+		}
+		if s.MaxItems != nil {
+			g.line("v.MaxItems(%s, len(%s), %d)", path, expr, *s.MaxItems)
+		}
+		if s.UniqueItems {
+			if g.model {
+				g.line("UniqueItems(v, %s, %s)", path, expr)
+			} else {
+				g.line("model.UniqueItems(v, %s, %s)", path, expr)
+			}
+		}
+		if s.Items != nil {
+			g.loop(expr, path, "i", "index", s.Items, depth)
+		}
+	case "object":
+		if s.MinProperties != nil {
+			g.line("v.MinProperties(%s, len(%s), %d)", path, expr, *s.MinProperties)
+		}
+		if s.MaxProperties != nil {
+			g.line("v.MaxProperties(%s, len(%s), %d)", path, expr, *s.MaxProperties)
+		}
+		if s.AdditionalProperties != nil {
+			g.loop(expr, path, "key", "field", s.AdditionalProperties, depth)
+		}
+	}
+}
+
+// loop writes a loop that validates the items or values of expr. Outside of
+// the model package the items are reported with the path of expr.
+func (g *validationGenerator) loop(expr, path, key, pathFunc string, s *Schema, depth int) {
+	key, item := fmt.Sprintf("%s%d", key, depth), fmt.Sprintf("item%d", depth)
+	itemPath := fmt.Sprintf("%s(%s, %s)", pathFunc, path, key)
+	if !g.model {
+		key, itemPath = "_", path
+	}
+
+	inner := &validationGenerator{model: g.model}
+	inner.value(item, itemPath, s, schemaType("", "", s, nil, true), false, depth+1)
+	if inner.Len() > 0 {
+		g.line("for %s, %s := range %s {", key, item, expr)
+		g.WriteString(inner.String())
+		g.line("}")
+	}
+}
+
+// countChecks returns true if the schema restricts the number of items or
+// properties.
+func countChecks(s *Schema) bool {
+	return s.MinItems != nil || s.MaxItems != nil || s.MinProperties != nil || s.MaxProperties != nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validation(t *testing.T) {
+	three := 3
+
+	type args struct {
+		name     string
+		s        *Schema
+		required []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{"no checks", args{"name", &Schema{Type: "string"}, []string{"name"}}, ""},
+		{"required pointer", args{"age", &Schema{Type: "integer"}, []string{"age"}}, ""},
+		{"optional minimum", args{"age", &Schema{Type: "integer", Minimum: 0}, nil}, "if m.Age != nil {\nv.Minimum(field(path, \"age\"), float64(*m.Age), 0)\n}\n"},
+		{"required length", args{"name", &Schema{Type: "string", MinLength: &three}, []string{"name"}}, "v.MinLength(field(path, \"name\"), m.Name, 3)\n"},
+		{"pattern", args{"name", &Schema{Type: "string", Pattern: "^[a-z]+$"}, []string{"name"}}, "v.Pattern(field(path, \"name\"), m.Name, \"^[a-z]+$\")\n"},
+		{"invalid pattern", args{"name", &Schema{Type: "string", Pattern: "(?<=a)b"}, []string{"name"}}, ""},
+		{"required array", args{"tags", &Schema{Type: "array", Items: &Schema{Type: "string"}}, []string{"tags"}}, "if m.Tags == nil {\nv.Required(field(path, \"tags\"))\n}\n"},
+		{"array items", args{"tags", &Schema{Type: "array", Items: &Schema{Type: "string", MinLength: &three}}, nil}, "for i0, item0 := range m.Tags {\nv.MinLength(index(field(path, \"tags\"), i0), item0, 3)\n}\n"},
+		{"min items", args{"tags", &Schema{Type: "array", MinItems: &three, Items: &Schema{Type: "string"}}, nil}, "if m.Tags != nil {\nv.MinItems(field(path, \"tags\"), len(m.Tags), 3)\n}\n"},
+		{"exclusive maximum", args{"age", &Schema{Type: "integer", Maximum: 10, ExclusiveMaximum: true, MultipleOf: 2}, []string{"age"}}, "v.ExclusiveMaximum(field(path, \"age\"), float64(m.Age), 10)\nv.MultipleOf(field(path, \"age\"), float64(m.Age), 2)\n"},
+		{"unique items", args{"tags", &Schema{Type: "array", UniqueItems: true, Items: &Schema{Type: "string"}}, nil}, "UniqueItems(v, field(path, \"tags\"), m.Tags)\n"},
+		{"max properties", args{"labels", &Schema{Type: "object", MaxProperties: &three, AdditionalProperties: &Schema{Type: "string"}}, nil}, "if m.Labels != nil {\nv.MaxProperties(field(path, \"labels\"), len(m.Labels), 3)\n}\n"},
+		{"format", args{"mail", &Schema{Type: "string", Format: "email"}, nil}, "if m.Mail != nil {\nv.Format(field(path, \"mail\"), *m.Mail, \"email\")\n}\n"},
+		{"ipv4", args{"ip", &Schema{Type: "string", Format: "ipv4"}, nil}, "if m.Ip != nil {\nv.IPv4(field(path, \"ip\"), m.Ip)\n}\n"},
+		{"ipv6", args{"ip", &Schema{Type: "string", Format: "ipv6"}, nil}, "if m.Ip != nil {\nv.IPv6(field(path, \"ip\"), m.Ip)\n}\n"},
+		{"ref", args{"user", &Schema{Ref: "#/definitions/User", resolved: &Schema{Type: "object"}}, nil}, "if m.User != nil {\nm.User.validate(v, field(path, \"user\"))\n}\n"},
+		{"required ref", args{"user", &Schema{Ref: "#/definitions/User", resolved: &Schema{Type: "object"}}, []string{"user"}}, "if m.User == nil {\nv.Required(field(path, \"user\"))\n} else {\nm.User.validate(v, field(path, \"user\"))\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, validation(tt.args.name, tt.args.s, tt.args.required), "validation(%v, %v, %v)", tt.args.name, tt.args.s, tt.args.required)
+		})
+	}
+}
+
+func Test_parameterValidation(t *testing.T) {
+	three := 3
+
+	type args struct {
+		parameter Parameter
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{"body", args{Parameter{Name: "user", In: "body", Schema: &Schema{Ref: "#/definitions/User"}}}, ""},
+		{"string", args{Parameter{Name: "id", In: "path", Required: true, Type: "string", MinLength: &three}}, "v.MinLength(\"id\", idP, 3)\n"},
+		{"optional string", args{Parameter{Name: "q", In: "query", Type: "string", MaxLength: &three}}, "if qP != \"\" {\nv.MaxLength(\"q\", qP, 3)\n}\n"},
+		{"optional integer", args{Parameter{Name: "limit", In: "query", Type: "integer", Maximum: 100}}, "if limitP != nil {\nv.Maximum(\"limit\", float64(*limitP), 100)\n}\n"},
+		{"header", args{Parameter{Name: "X-Token", In: "header", Required: true, Type: "string", MinLength: &three}}, "v.MinLength(\"X-Token\", xTokenP, 3)\n"},
+		{"required array", args{Parameter{Name: "X-Tags", In: "header", Required: true, Type: "array", Items: &Schema{Type: "string"}}}, "if xTagsP == nil {\nv.Required(\"X-Tags\")\n}\n"},
+		{"array items", args{Parameter{Name: "tags", In: "query", Type: "array", MaxItems: &three, Items: &Schema{Type: "string", MinLength: &three}}}, "if tagsP != nil {\nv.MaxItems(\"tags\", len(tagsP), 3)\nfor _, item0 := range tagsP {\nv.MinLength(\"tags\", item0, 3)\n}\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equalf(t, tt.want, parameterValidation(tt.args.parameter), "parameterValidation(%v)", tt.args.parameter)
+		})
+	}
+}